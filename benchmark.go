@@ -0,0 +1,64 @@
+package neatflappy
+
+import (
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// benchmarkMaxTicks caps every benchmark flight, so perfect flyers do not run forever
+const benchmarkMaxTicks = 60 * 60 * 5
+
+// BenchmarkLevels returns the fixed suite of levels used for ranking genomes. Only the
+// deterministic levels are used, so the results are comparable across runs.
+func BenchmarkLevels() []Level {
+	return []Level{Level1(1), Level1(level2 / 2), Level1(level2 - 1)}
+}
+
+// Benchmark flies genomes over a fixed suite of levels
+type Benchmark struct {
+	// Translator builds the network of the decoded genomes
+	Translator evo.Translator
+	// Levels returns fresh instances of the levels to fly. BenchmarkLevels is used if nil.
+	Levels func() []Level
+}
+
+// Run flies the genome over every level of the suite
+func (b Benchmark) Run(g evo.Genome) ([]Outcome, error) {
+	net, err := b.Translator.Translate(g.Decoded)
+	if err != nil {
+		return nil, err
+	}
+	return b.Fly(&evoJumper{evo.Phenome{ID: g.ID, Traits: g.Traits, Network: net}}), nil
+}
+
+// Fly flies the jumper over every level of the suite
+func (b Benchmark) Fly(jumper Jumper) []Outcome {
+	levels := BenchmarkLevels
+	if b.Levels != nil {
+		levels = b.Levels
+	}
+
+	outcomes := []Outcome{}
+	for _, l := range levels() {
+		outcomes = append(outcomes, Simulate(l, jumper, benchmarkMaxTicks))
+	}
+	return outcomes
+}
+
+// Scores runs the benchmark and returns the results as expected by store.HallOfFame
+func (b Benchmark) Scores(g evo.Genome) ([]store.LevelScore, error) {
+	outcomes, err := b.Run(g)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]store.LevelScore, len(outcomes))
+	for i, o := range outcomes {
+		scores[i] = store.LevelScore{
+			Level:    o.Level,
+			Score:    o.Score,
+			Distance: o.Distance,
+			Pipes:    o.Pipes,
+		}
+	}
+	return scores, nil
+}
@@ -3,26 +3,37 @@ package bolt
 import (
 	"bytes"
 	"encoding/binary"
-	"encoding/gob"
 	"errors"
 	"fmt"
 
 	"github.com/boltdb/bolt"
+	"github.com/kpacha/neatflappy/store"
 )
 
 const (
 	PhenomeBucket    = "PhenomeBucket"
 	GenerationBucket = "GenerationBucket"
 	PopulationBucket = "PopulationBucket"
+	RunBucket        = "RunBucket"
+	HallOfFameBucket = "HallOfFameBucket"
+	MetaBucket       = "MetaBucket"
 )
 
 var (
-	buckets          = []string{PhenomeBucket, GenerationBucket, PopulationBucket}
+	buckets          = []string{PhenomeBucket, GenerationBucket, PopulationBucket, RunBucket, HallOfFameBucket, MetaBucket}
 	ErrUnknownBucket = errors.New("unknown bucket")
+	ErrNotFound      = store.ErrNotFound
 )
 
+// New opens the default database, my.db, in the working directory
 func New() (*Client, error) {
-	db, err := bolt.Open("my.db", 0600, nil)
+	return Open("my.db")
+}
+
+// Open opens the database at path, creating it and its buckets if required.
+// Databases written by older versions are migrated to the current layout.
+func Open(path string) (*Client, error) {
+	db, err := bolt.Open(path, 0600, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -39,12 +50,19 @@ func New() (*Client, error) {
 			return nil, err
 		}
 	}
+	if err := db.Update(migrate); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-	return &Client{db}, nil
+	return &Client{db: db, Codec: store.Gob}, nil
 }
 
 type Client struct {
 	db *bolt.DB
+	// Codec is used for encoding the records. Records are always decoded
+	// with the codec recorded in their envelope.
+	Codec store.Codec
 }
 
 func (c *Client) Close() error {
@@ -56,13 +74,13 @@ func (c *Client) Update(bucket string, key []byte, v interface{}) error {
 		return err
 	}
 
+	data, err := store.Marshal(c.Codec, v)
+	if err != nil {
+		return err
+	}
+
 	return c.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(bucket))
-		buf := new(bytes.Buffer)
-		if err := gob.NewEncoder(buf).Encode(v); err != nil {
-			return err
-		}
-		return b.Put(key, buf.Bytes())
+		return tx.Bucket([]byte(bucket)).Put(key, data)
 	})
 }
 
@@ -72,12 +90,103 @@ func (c *Client) Get(bucket string, key []byte, v interface{}) error {
 	}
 
 	return c.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(bucket))
-		buf := bytes.NewBuffer(b.Get(key))
-		return gob.NewDecoder(buf).Decode(v)
+		data := tx.Bucket([]byte(bucket)).Get(key)
+		if data == nil {
+			return ErrNotFound
+		}
+		return Decode(data, v)
+	})
+}
+
+// Delete removes the key from the bucket. Deleting a missing key is not an error.
+func (c *Client) Delete(bucket string, key []byte) error {
+	if err := c.checkBucket(bucket); err != nil {
+		return err
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucket)).Delete(key)
+	})
+}
+
+// Count returns the number of keys stored in the bucket
+func (c *Client) Count(bucket string) (int, error) {
+	if err := c.checkBucket(bucket); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	err := c.db.View(func(tx *bolt.Tx) error {
+		total = tx.Bucket([]byte(bucket)).Stats().KeyN
+		return nil
+	})
+	return total, err
+}
+
+// List returns all the keys of the bucket in byte-sorted order
+func (c *Client) List(bucket string) ([][]byte, error) {
+	keys := [][]byte{}
+	err := c.ForEach(bucket, func(k, _ []byte) error {
+		key := make([]byte, len(k))
+		copy(key, k)
+		keys = append(keys, key)
+		return nil
+	})
+	return keys, err
+}
+
+// ForEach calls fn for every key/value pair of the bucket in byte-sorted order.
+// The slices are only valid during the call, so fn must copy them or Decode the
+// value before returning. Returning an error from fn stops the iteration.
+func (c *Client) ForEach(bucket string, fn func(k, v []byte) error) error {
+	return c.ForEachRange(bucket, nil, nil, fn)
+}
+
+// ForEachPrefix calls fn for every key/value pair of the bucket whose key starts with prefix
+func (c *Client) ForEachPrefix(bucket string, prefix []byte, fn func(k, v []byte) error) error {
+	if err := c.checkBucket(bucket); err != nil {
+		return err
+	}
+
+	return c.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(bucket)).Cursor()
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ForEachRange calls fn for every key/value pair of the bucket with min <= key < max.
+// A nil min starts from the first key and a nil max runs until the last one.
+func (c *Client) ForEachRange(bucket string, min, max []byte, fn func(k, v []byte) error) error {
+	if err := c.checkBucket(bucket); err != nil {
+		return err
+	}
+
+	return c.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(bucket)).Cursor()
+		k, v := cursor.First()
+		if min != nil {
+			k, v = cursor.Seek(min)
+		}
+		for ; k != nil && (max == nil || bytes.Compare(k, max) < 0); k, v = cursor.Next() {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
+// Decode decodes a raw value as returned by the iteration methods into v.
+// Values stored before the versioned envelopes are decoded as plain gob.
+func Decode(data []byte, v interface{}) error {
+	return store.Unmarshal(data, v)
+}
+
 func (c *Client) checkBucket(name string) error {
 	offset := -1
 	for k, b := range buckets {
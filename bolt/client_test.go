@@ -1,14 +1,22 @@
 package bolt
 
 import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/store"
 )
 
 func TestClient(t *testing.T) {
-	client, err := New()
-	defer os.Remove("my.db")
+	client, cleanup, err := newTestClient()
+	defer cleanup()
 	if err != nil {
 		t.Error(err)
 		return
@@ -40,3 +48,164 @@ type A struct {
 	P2 int
 	P3 bool
 }
+
+func TestClient_iterate(t *testing.T) {
+	client, cleanup, err := newTestClient()
+	defer cleanup()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer client.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := client.Update(PhenomeBucket, itob(uint64(i)), A{P2: i}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if n, err := client.Count(PhenomeBucket); err != nil || n != 10 {
+		t.Errorf("unexpected count: %d, %v", n, err)
+	}
+
+	seen := []int{}
+	err = client.ForEachRange(PhenomeBucket, itob(3), itob(6), func(_, v []byte) error {
+		a := A{}
+		if err := Decode(v, &a); err != nil {
+			return err
+		}
+		seen = append(seen, a.P2)
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(seen, []int{3, 4, 5}) {
+		t.Errorf("unexpected range: %v", seen)
+	}
+
+	if err := client.Delete(PhenomeBucket, itob(3)); err != nil {
+		t.Error(err)
+	}
+	if err := client.Get(PhenomeBucket, itob(3), new(A)); err != ErrNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	keys, err := client.List(PhenomeBucket)
+	if err != nil || len(keys) != 9 {
+		t.Errorf("unexpected keys: %v, %v", keys, err)
+	}
+}
+
+func TestClient_runs(t *testing.T) {
+	client, cleanup, err := newTestClient()
+	defer cleanup()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer client.Close()
+
+	run1, _ := client.NewRun()
+	run2, _ := client.NewRun()
+	if run1 == run2 {
+		t.Errorf("duplicated run id %d", run1)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := client.PutGeneration(store.Generation{Run: run1, Generation: i, Best: int64(i)}); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := client.PutGeneration(store.Generation{Run: run2, Generation: 0, Best: 42}); err != nil {
+		t.Error(err)
+	}
+
+	generations, err := client.Generations(run1)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(generations) != 3 || generations[2].Best != 2 {
+		t.Errorf("unexpected generations: %+v", generations)
+	}
+
+	runs, err := client.Runs()
+	if err != nil || !reflect.DeepEqual(runs, []int64{run1, run2}) {
+		t.Errorf("unexpected runs: %v, %v", runs, err)
+	}
+
+	if _, err := client.BestOfRun(run1); err != ErrNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the best genomes are the last ones, so the ranking does not depend on the sorting criteria
+	for i, f := range []float64{1, 3, 5} {
+		if err := client.PutGenome(run1, evo.Genome{ID: int64(i + 1), Fitness: f}); err != nil {
+			t.Error(err)
+		}
+	}
+	for i, f := range []float64{2, 4} {
+		if err := client.PutGenome(run2, evo.Genome{ID: int64(i + 1), Fitness: f}); err != nil {
+			t.Error(err)
+		}
+	}
+	for run, want := range map[int64]float64{run1: 5, run2: 4} {
+		best, err := client.BestOfRun(run)
+		if err != nil || best.Fitness != want {
+			t.Errorf("unexpected best of run %d: %+v, %v", run, best, err)
+		}
+	}
+
+	keys, err := client.List(PhenomeBucket)
+	if err != nil || len(keys) != 5 {
+		t.Errorf("unexpected keys: %v, %v", keys, err)
+	}
+	for i, k := range keys {
+		run := run1
+		if i >= 3 {
+			run = run2
+		}
+		if !bytes.Equal(k[:8], itob(uint64(run))) {
+			t.Errorf("key %x out of run %d", k, run)
+		}
+	}
+}
+
+func TestClient_codecs(t *testing.T) {
+	client, cleanup, err := newTestClient()
+	defer cleanup()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer client.Close()
+
+	// records written before the envelopes are raw gob values
+	buf := new(bytes.Buffer)
+	gob.NewEncoder(buf).Encode(A{P1: "legacy"})
+	client.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(PhenomeBucket)).Put([]byte("legacy"), buf.Bytes())
+	})
+
+	client.Codec = store.JSON
+	if err := client.Update(PhenomeBucket, []byte("json"), A{P1: "json"}); err != nil {
+		t.Error(err)
+	}
+
+	for _, key := range []string{"legacy", "json"} {
+		a := A{}
+		if err := client.Get(PhenomeBucket, []byte(key), &a); err != nil || a.P1 != key {
+			t.Errorf("unexpected record for %s: %+v, %v", key, a, err)
+		}
+	}
+}
+
+func newTestClient() (*Client, func(), error) {
+	dir, err := ioutil.TempDir("", "neatflappy-bolt")
+	if err != nil {
+		return nil, func() {}, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	client, err := Open(filepath.Join(dir, "test.db"))
+	return client, cleanup, err
+}
@@ -0,0 +1,84 @@
+package bolt
+
+import (
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// LegacyRun is the run holding the genomes stored before the runs were tracked
+const LegacyRun = 0
+
+// legacyLayout is the version of the databases written before the schema was recorded
+const legacyLayout = 0
+
+var schemaKey = []byte("schema")
+
+// layoutMigrations upgrade the keys of a database written with the schema version
+// used as index to the next one, as the migrations registered in the store package
+// do for the payloads
+var layoutMigrations = map[int]func(tx *bolt.Tx) error{
+	legacyLayout: migrateRunKeys,
+}
+
+// migrate brings the database to store.SchemaVersion and records it, so the
+// migrations run only once
+func migrate(tx *bolt.Tx) error {
+	meta := tx.Bucket([]byte(MetaBucket))
+	version := legacyLayout
+	if v := meta.Get(schemaKey); v != nil {
+		version = int(btoi(v))
+	}
+	if version > store.SchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than the supported one (%d)", version, store.SchemaVersion)
+	}
+	for ; version < store.SchemaVersion; version++ {
+		m, ok := layoutMigrations[version]
+		if !ok {
+			continue
+		}
+		if err := m(tx); err != nil {
+			return err
+		}
+	}
+	return meta.Put(schemaKey, itob(uint64(store.SchemaVersion)))
+}
+
+// migrateRunKeys moves the genomes stored with the bare genome ID as key into the
+// LegacyRun, so they are reachable with the run keys
+func migrateRunKeys(tx *bolt.Tx) error {
+	b := tx.Bucket([]byte(PhenomeBucket))
+	legacy := [][]byte{}
+	if err := b.ForEach(func(k, _ []byte) error {
+		if len(k) == 8 {
+			legacy = append(legacy, append([]byte{}, k...))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(legacy) == 0 {
+		return nil
+	}
+
+	for _, k := range legacy {
+		v := append([]byte{}, b.Get(k)...)
+		if err := b.Put(runKey(LegacyRun, int64(btoi(k))), v); err != nil {
+			return err
+		}
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	runs := tx.Bucket([]byte(RunBucket))
+	if runs.Get(itob(LegacyRun)) != nil {
+		return nil
+	}
+	data, err := store.Marshal(store.Gob, store.Run{ID: LegacyRun})
+	if err != nil {
+		return err
+	}
+	return runs.Put(itob(LegacyRun), data)
+}
@@ -0,0 +1,97 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// writeBaseline creates a database as the baseline did: three buckets and the
+// genomes gob encoded under their bare ID
+func writeBaseline(path string, genomes ...evo.Genome) error {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range []string{PhenomeBucket, GenerationBucket, PopulationBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		for _, g := range genomes {
+			buf := new(bytes.Buffer)
+			if err := gob.NewEncoder(buf).Encode(g); err != nil {
+				return err
+			}
+			if err := tx.Bucket([]byte(PhenomeBucket)).Put(itob(uint64(g.ID)), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestOpen_baseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neatflappy-bolt")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "my.db")
+	if err := writeBaseline(path, evo.Genome{ID: 7, Fitness: 3}, evo.Genome{ID: 9, Fitness: 5}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	for i := 0; i < 2; i++ {
+		client, err := Open(path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		g, err := client.Genome(LegacyRun, 7)
+		if err != nil || g.Fitness != 3 {
+			t.Errorf("open %d: unexpected legacy genome: %+v, %v", i, g, err)
+		}
+		if best, err := client.BestOfRun(LegacyRun); err != nil || best.ID != 9 {
+			t.Errorf("open %d: unexpected best: %+v, %v", i, best, err)
+		}
+		runs, err := client.Runs()
+		if err != nil || !reflect.DeepEqual(runs, []int64{LegacyRun}) {
+			t.Errorf("open %d: unexpected runs: %v, %v", i, runs, err)
+		}
+		if n, err := client.Count(PhenomeBucket); err != nil || n != 2 {
+			t.Errorf("open %d: unexpected genomes: %d, %v", i, n, err)
+		}
+		client.Close()
+	}
+}
+
+func TestOpen_newerSchema(t *testing.T) {
+	client, cleanup, err := newTestClient()
+	defer cleanup()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	client.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(MetaBucket)).Put(schemaKey, itob(store.SchemaVersion+1))
+	})
+	path := client.db.Path()
+	client.Close()
+
+	if _, err := Open(path); err == nil {
+		t.Error("database with a newer schema opened")
+	}
+}
@@ -0,0 +1,126 @@
+package bolt
+
+import (
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/store"
+)
+
+var _ store.Store = (*Client)(nil)
+
+// NewRun registers a new run and returns its ID
+func (c *Client) NewRun() (int64, error) {
+	var id int64
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(RunBucket))
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		id = int64(seq)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, c.Update(RunBucket, itob(uint64(id)), store.Run{ID: id, Started: time.Now()})
+}
+
+// Runs returns the IDs of all the registered runs
+func (c *Client) Runs() ([]int64, error) {
+	runs := []int64{}
+	err := c.ForEach(RunBucket, func(k, _ []byte) error {
+		runs = append(runs, int64(btoi(k)))
+		return nil
+	})
+	return runs, err
+}
+
+// PutGenome stores the genome as part of the run
+func (c *Client) PutGenome(runID int64, g evo.Genome) error {
+	return c.Update(PhenomeBucket, runKey(runID, g.ID), g)
+}
+
+// Genome returns the genome id stored for the run
+func (c *Client) Genome(runID, id int64) (evo.Genome, error) {
+	g := evo.Genome{}
+	err := c.Get(PhenomeBucket, runKey(runID, id), &g)
+	return g, err
+}
+
+// Genomes returns all the genomes stored for the run, sorted by ID
+func (c *Client) Genomes(runID int64) ([]evo.Genome, error) {
+	genomes := []evo.Genome{}
+	err := c.ForEachPrefix(PhenomeBucket, itob(uint64(runID)), func(_, v []byte) error {
+		g := evo.Genome{}
+		if err := Decode(v, &g); err != nil {
+			return err
+		}
+		genomes = append(genomes, g)
+		return nil
+	})
+	return genomes, err
+}
+
+// BestOfRun returns the best genome stored for the run, using the same criteria as store.Evo.StoreBest
+func (c *Client) BestOfRun(runID int64) (evo.Genome, error) {
+	genomes, err := c.Genomes(runID)
+	if err != nil {
+		return evo.Genome{}, err
+	}
+	return store.Best(genomes)
+}
+
+// PutGeneration stores the summary of a generation
+func (c *Client) PutGeneration(g store.Generation) error {
+	return c.Update(GenerationBucket, runKey(g.Run, int64(g.Generation)), g)
+}
+
+// Generations returns the summaries stored for the run, sorted by generation
+func (c *Client) Generations(runID int64) ([]store.Generation, error) {
+	generations := []store.Generation{}
+	err := c.ForEachPrefix(GenerationBucket, itob(uint64(runID)), func(_, v []byte) error {
+		g := store.Generation{}
+		if err := Decode(v, &g); err != nil {
+			return err
+		}
+		generations = append(generations, g)
+		return nil
+	})
+	return generations, err
+}
+
+// PutPopulation stores a checkpoint of the whole population
+func (c *Client) PutPopulation(runID int64, pop evo.Population) error {
+	return c.Update(PopulationBucket, runKey(runID, int64(pop.Generation)), pop)
+}
+
+// Population returns the checkpoint of the given generation
+func (c *Client) Population(runID int64, generation int) (evo.Population, error) {
+	pop := evo.Population{}
+	err := c.Get(PopulationBucket, runKey(runID, int64(generation)), &pop)
+	return pop, err
+}
+
+var hallOfFameKey = []byte("entries")
+
+// PutHallOfFame replaces the hall of fame with the given entries
+func (c *Client) PutHallOfFame(entries []store.Fame) error {
+	return c.Update(HallOfFameBucket, hallOfFameKey, entries)
+}
+
+// HallOfFame returns the entries of the hall of fame, best first
+func (c *Client) HallOfFame() ([]store.Fame, error) {
+	entries := []store.Fame{}
+	err := c.Get(HallOfFameBucket, hallOfFameKey, &entries)
+	if err == ErrNotFound {
+		return entries, nil
+	}
+	return entries, err
+}
+
+func runKey(runID, id int64) []byte {
+	return append(itob(uint64(runID)), itob(uint64(id))...)
+}
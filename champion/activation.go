@@ -0,0 +1,49 @@
+package champion
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Activations are the supported activation functions, indexed by the names used
+// in the evo configuration files
+var Activations = map[string]func(float64) float64{
+	"direct":            func(x float64) float64 { return x },
+	"linear":            func(x float64) float64 { return x },
+	"sigmoid":           func(x float64) float64 { return 1.0 / (1.0 + math.Exp(-x)) },
+	"steepened-sigmoid": func(x float64) float64 { return 1.0 / (1.0 + math.Exp(-4.9*x)) },
+	"tanh":              math.Tanh,
+	"inverse-abs":       func(x float64) float64 { return x / (1.0 + math.Abs(x)) },
+	"sin":               math.Sin,
+	"gauss":             func(x float64) float64 { return math.Exp(-2.0 * x * x) },
+	"relu":              func(x float64) float64 { return math.Max(0, x) },
+	"step": func(x float64) float64 {
+		if x <= 0 {
+			return 0
+		}
+		return 1
+	},
+}
+
+// normalize accepts the spellings "SteepenedSigmoid", "steepened_sigmoid" and "steepened-sigmoid".
+// A capital letter starts a new word only after a lower case one and before another, so
+// the acronyms as in "ReLU" keep their letters together.
+func normalize(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == ' ':
+			b.WriteRune('-')
+		case unicode.IsUpper(r):
+			if i > 0 && unicode.IsLower(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				b.WriteRune('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,291 @@
+// Package champion defines a portable JSON format for the trained networks and a
+// pure Go implementation able to run them without the NEAT machinery.
+//
+// A network file looks like this:
+//
+//	{
+//		"format": 1,
+//		"id": 1234,
+//		"run": 3,
+//		"species": 7,
+//		"fitness": 1523.5,
+//		"inputs": ["pipe1 dx", "pipe1 dy", "pipe2 dx", "pipe2 dy", "altitude", "velocity", "bias"],
+//		"nodes": [
+//			{"id": 0, "kind": "input", "activation": "direct", "bias": 0, "layer": 0, "x": 0},
+//			...
+//			{"id": 7, "kind": "output", "activation": "steepened-sigmoid", "bias": 0.3, "layer": 1, "x": 0.5}
+//		],
+//		"conns": [
+//			{"source": 0, "target": 7, "weight": -1.2, "enabled": true},
+//			...
+//		]
+//	}
+//
+// Nodes are sorted by layer and, inside a layer, by position. The input nodes
+// receive the inputs in that order, the outputs are returned in that order too.
+// Every non-input node computes activation(bias + sum(weight * source)) over its
+// enabled incoming connections. Nodes are evaluated layer by layer and no state is
+// kept between activations, so connections from a node not evaluated yet, in the
+// same or a later layer, read zero.
+package champion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/klokare/evo"
+	"gonum.org/v1/gonum/mat"
+)
+
+// FormatVersion is the version of the network files written by this package
+const FormatVersion = 1
+
+const (
+	KindInput  = "input"
+	KindHidden = "hidden"
+	KindOutput = "output"
+)
+
+var ErrNoOutputs = errors.New("the network has no outputs")
+
+// Network is a portable, self-contained neural network
+type Network struct {
+	Format  int      `json:"format"`
+	ID      int64    `json:"id"`
+	Run     int64    `json:"run,omitempty"`
+	Species int64    `json:"species"`
+	Fitness float64  `json:"fitness"`
+	Inputs  []string `json:"inputs,omitempty"`
+	Nodes   []Node   `json:"nodes"`
+	Conns   []Conn   `json:"conns"`
+
+	inputs   []int
+	outputs  []int
+	incoming [][]Conn
+	acts     []func(float64) float64
+}
+
+// Node is a neuron of the network
+type Node struct {
+	ID         int     `json:"id"`
+	Kind       string  `json:"kind"`
+	Activation string  `json:"activation"`
+	Bias       float64 `json:"bias"`
+	Layer      float64 `json:"layer"`
+	X          float64 `json:"x"`
+}
+
+// Conn is a weighted connection between two nodes, referenced by their IDs
+type Conn struct {
+	Source  int     `json:"source"`
+	Target  int     `json:"target"`
+	Weight  float64 `json:"weight"`
+	Enabled bool    `json:"enabled"`
+}
+
+// FromGenome converts the decoded substrate of the genome into a network.
+// The encoded substrate is used if the genome was not decoded.
+func FromGenome(g evo.Genome) (*Network, error) {
+	sub := g.Decoded
+	if len(sub.Nodes) == 0 {
+		sub = g.Encoded
+	}
+	n, err := FromSubstrate(sub)
+	if err != nil {
+		return nil, err
+	}
+	n.ID = g.ID
+	n.Species = g.Species
+	n.Fitness = g.Fitness
+	return n, nil
+}
+
+// FromSubstrate converts the substrate into a network
+func FromSubstrate(sub evo.Substrate) (*Network, error) {
+	nodes := make([]evo.Node, len(sub.Nodes))
+	copy(nodes, sub.Nodes)
+	sort.SliceStable(nodes, func(i, j int) bool { return less(nodes[i].Position, nodes[j].Position) })
+
+	n := &Network{Format: FormatVersion}
+	index := map[evo.Position]int{}
+	for i, node := range nodes {
+		index[node.Position] = i
+		kind := KindHidden
+		switch node.Neuron {
+		case evo.Input:
+			kind = KindInput
+		case evo.Output:
+			kind = KindOutput
+		}
+		n.Nodes = append(n.Nodes, Node{
+			ID:         i,
+			Kind:       kind,
+			Activation: node.Activation.String(),
+			Bias:       node.Bias,
+			Layer:      node.Position.Layer,
+			X:          node.Position.X,
+		})
+	}
+
+	for _, c := range sub.Conns {
+		source, ok := index[c.Source]
+		if !ok {
+			return nil, fmt.Errorf("unknown source node at %+v", c.Source)
+		}
+		target, ok := index[c.Target]
+		if !ok {
+			return nil, fmt.Errorf("unknown target node at %+v", c.Target)
+		}
+		n.Conns = append(n.Conns, Conn{Source: source, Target: target, Weight: c.Weight, Enabled: c.Enabled})
+	}
+
+	return n, n.compile()
+}
+
+// Load reads a network file and prepares it for activation
+func Load(r io.Reader) (*Network, error) {
+	n := new(Network)
+	if err := json.NewDecoder(r).Decode(n); err != nil {
+		return nil, err
+	}
+	if n.Format > FormatVersion {
+		return nil, fmt.Errorf("unsupported network format %d", n.Format)
+	}
+	return n, n.compile()
+}
+
+// Write stores the network as an indented JSON document
+func (n *Network) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(n)
+}
+
+// compile validates the network and caches what the activation needs
+func (n *Network) compile() error {
+	n.inputs, n.outputs = nil, nil
+	n.incoming = make([][]Conn, len(n.Nodes))
+	n.acts = make([]func(float64) float64, len(n.Nodes))
+
+	for i, node := range n.Nodes {
+		if node.ID != i {
+			return fmt.Errorf("node %d has the id %d", i, node.ID)
+		}
+		if i > 0 && node.Layer < n.Nodes[i-1].Layer {
+			return fmt.Errorf("node %d is not sorted by layer", i)
+		}
+		act, ok := Activations[normalize(node.Activation)]
+		if !ok && node.Kind != KindInput {
+			return fmt.Errorf("node %d: unknown activation %q", i, node.Activation)
+		}
+		n.acts[i] = act
+		switch node.Kind {
+		case KindInput:
+			n.inputs = append(n.inputs, i)
+		case KindOutput:
+			n.outputs = append(n.outputs, i)
+		case KindHidden:
+		default:
+			return fmt.Errorf("node %d: unknown kind %q", i, node.Kind)
+		}
+	}
+	if len(n.outputs) == 0 {
+		return ErrNoOutputs
+	}
+
+	for _, c := range n.Conns {
+		if c.Source < 0 || c.Source >= len(n.Nodes) || c.Target < 0 || c.Target >= len(n.Nodes) {
+			return fmt.Errorf("connection %d -> %d points to an unknown node", c.Source, c.Target)
+		}
+		if n.Nodes[c.Target].Kind == KindInput {
+			return fmt.Errorf("connection %d -> %d targets an input node", c.Source, c.Target)
+		}
+		if c.Enabled {
+			n.incoming[c.Target] = append(n.incoming[c.Target], c)
+		}
+	}
+	return nil
+}
+
+// NumInputs returns the number of input nodes
+func (n *Network) NumInputs() int {
+	return len(n.inputs)
+}
+
+// Values activates the network with the inputs and returns the value of every node.
+// Missing inputs are considered zero and the extra ones are ignored.
+func (n *Network) Values(in []float64) []float64 {
+	values := make([]float64, len(n.Nodes))
+	for k, i := range n.inputs {
+		if k < len(in) {
+			values[i] = in[k]
+		}
+	}
+	for i, node := range n.Nodes {
+		if node.Kind == KindInput {
+			continue
+		}
+		sum := node.Bias
+		for _, c := range n.incoming[i] {
+			sum += c.Weight * values[c.Source]
+		}
+		values[i] = n.acts[i](sum)
+	}
+	return values
+}
+
+// Compute activates the network with the inputs and returns the outputs
+func (n *Network) Compute(in []float64) []float64 {
+	values := n.Values(in)
+	out := make([]float64, len(n.outputs))
+	for k, i := range n.outputs {
+		out[k] = values[i]
+	}
+	return out
+}
+
+// Activate processes every row of the matrix as a set of inputs, so the network can be used as an evo.Network
+func (n *Network) Activate(inputs evo.Matrix) (evo.Matrix, error) {
+	rows, cols := inputs.Dims()
+	if rows == 0 {
+		return nil, errors.New("no inputs to activate")
+	}
+	outputs := mat.NewDense(rows, len(n.outputs), nil)
+	in := make([]float64, cols)
+	for r := 0; r < rows; r++ {
+		for c := range in {
+			in[c] = inputs.At(r, c)
+		}
+		outputs.SetRow(r, n.Compute(in))
+	}
+	return outputs, nil
+}
+
+// Jump implements the Jumper interface of the game, using the same threshold as the evolved networks
+func (n *Network) Jump(in []float64) bool {
+	return n.Compute(in)[0] > 0.5
+}
+
+// Translator builds networks from substrates, so it can replace the evo translator
+type Translator struct{}
+
+// Translate the substrate into a network
+func (Translator) Translate(sub evo.Substrate) (evo.Network, error) {
+	return FromSubstrate(sub)
+}
+
+func less(a, b evo.Position) bool {
+	if a.Layer != b.Layer {
+		return a.Layer < b.Layer
+	}
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.Z < b.Z
+}
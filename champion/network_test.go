@@ -0,0 +1,194 @@
+package champion
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/klokare/evo"
+	"github.com/klokare/evo/network"
+	"gonum.org/v1/gonum/mat"
+)
+
+// sample is a xor-like network: out = step(a + b - 2*hidden - 0.5), hidden = step(a + b - 1.5)
+const sample = `{
+	"format": 1,
+	"id": 42,
+	"nodes": [
+		{"id": 0, "kind": "input", "activation": "direct", "layer": 0, "x": 0},
+		{"id": 1, "kind": "input", "activation": "direct", "layer": 0, "x": 1},
+		{"id": 2, "kind": "hidden", "activation": "step", "bias": -1.5, "layer": 0.5, "x": 0.5},
+		{"id": 3, "kind": "output", "activation": "Step", "bias": -0.5, "layer": 1, "x": 0.5}
+	],
+	"conns": [
+		{"source": 0, "target": 2, "weight": 1, "enabled": true},
+		{"source": 1, "target": 2, "weight": 1, "enabled": true},
+		{"source": 0, "target": 3, "weight": 1, "enabled": true},
+		{"source": 1, "target": 3, "weight": 1, "enabled": true},
+		{"source": 2, "target": 3, "weight": -2, "enabled": true},
+		{"source": 0, "target": 3, "weight": 100, "enabled": false}
+	]
+}`
+
+func TestLoad(t *testing.T) {
+	n, err := Load(strings.NewReader(sample))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if n.ID != 42 || n.NumInputs() != 2 {
+		t.Errorf("unexpected network: %+v", n)
+	}
+
+	for _, tc := range []struct {
+		in  []float64
+		out bool
+	}{
+		{in: []float64{0, 0}, out: false},
+		{in: []float64{0, 1}, out: true},
+		{in: []float64{1, 0}, out: true},
+		{in: []float64{1, 1}, out: false},
+	} {
+		if out := n.Jump(tc.in); out != tc.out {
+			t.Errorf("%v: unexpected output %v", tc.in, out)
+		}
+	}
+
+	outputs, err := n.Activate(mat.NewDense(2, 2, []float64{0, 1, 1, 1}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if outputs.At(0, 0) != 1 || outputs.At(1, 0) != 0 {
+		t.Errorf("unexpected outputs: %v", mat.Formatted(outputs.(*mat.Dense)))
+	}
+
+	buf := new(bytes.Buffer)
+	if err := n.Write(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	reloaded, err := Load(buf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if reloaded.Compute([]float64{1, 0})[0] != 1 {
+		t.Error("the reloaded network does not behave as the original one")
+	}
+}
+
+func TestLoad_invalid(t *testing.T) {
+	for name, doc := range map[string]string{
+		"activation": `{"nodes":[{"id":0,"kind":"output","activation":"unknown"}]}`,
+		"outputs":    `{"nodes":[{"id":0,"kind":"input","activation":"direct"}]}`,
+		"conn":       `{"nodes":[{"id":0,"kind":"output","activation":"sigmoid"}],"conns":[{"source":3,"target":0}]}`,
+		"format":     `{"format":99,"nodes":[{"id":0,"kind":"output","activation":"sigmoid"}]}`,
+		"order":      `{"nodes":[{"id":0,"kind":"output","activation":"sigmoid","layer":1},{"id":1,"kind":"input","layer":0}]}`,
+	} {
+		if _, err := Load(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: error expected", name)
+		}
+	}
+}
+
+func TestActivations(t *testing.T) {
+	for _, name := range []string{"SteepenedSigmoid", "steepened_sigmoid", "steepened-sigmoid"} {
+		act, ok := Activations[normalize(name)]
+		if !ok {
+			t.Errorf("%s: unknown activation", name)
+			continue
+		}
+		if v := act(0); math.Abs(v-0.5) > 1e-9 {
+			t.Errorf("%s: unexpected value %f", name, v)
+		}
+	}
+	for _, name := range []string{"ReLU", "RELU", "relu"} {
+		act, ok := Activations[normalize(name)]
+		if !ok {
+			t.Errorf("%s: unknown activation", name)
+			continue
+		}
+		if v := act(-1); v != 0 {
+			t.Errorf("%s: unexpected value %f", name, v)
+		}
+	}
+}
+
+// mutatedGenome is a genome as the NEAT mutations leave it: an add-node split the
+// connection from the first input into two through a hidden node, disabling it,
+// and an add-conn linked the second input to the new node
+func mutatedGenome() evo.Genome {
+	in0 := evo.Position{Layer: 0, X: 0}
+	in1 := evo.Position{Layer: 0, X: 0.5}
+	bias := evo.Position{Layer: 0, X: 1}
+	hidden := evo.Position{Layer: 0.5, X: 0.25}
+	out := evo.Position{Layer: 1, X: 0.5}
+	sub := evo.Substrate{
+		Nodes: []evo.Node{
+			{Position: in0, Neuron: evo.Input, Activation: evo.Direct},
+			{Position: in1, Neuron: evo.Input, Activation: evo.Direct},
+			{Position: bias, Neuron: evo.Input, Activation: evo.Direct},
+			{Position: hidden, Neuron: evo.Hidden, Activation: evo.SteepenedSigmoid, Bias: -0.3},
+			{Position: out, Neuron: evo.Output, Activation: evo.SteepenedSigmoid, Bias: 0.2},
+		},
+		Conns: []evo.Conn{
+			{Source: in0, Target: out, Weight: 1.7, Enabled: false},
+			{Source: in0, Target: hidden, Weight: 1, Enabled: true},
+			{Source: hidden, Target: out, Weight: 1.7, Enabled: true},
+			{Source: in1, Target: hidden, Weight: -2.4, Enabled: true},
+			{Source: in1, Target: out, Weight: 0.6, Enabled: true},
+			{Source: bias, Target: out, Weight: -0.9, Enabled: true},
+		},
+	}
+	return evo.Genome{ID: 3, Encoded: sub, Decoded: sub}
+}
+
+// TestFromGenome_parity checks the networks fly as the ones translated by evo
+func TestFromGenome_parity(t *testing.T) {
+	g := mutatedGenome()
+	want, err := network.Translator{}.Translate(g.Decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FromGenome(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := [][]float64{{0, 0, 1}, {1, 0, 1}, {0, 1, 1}, {1, 1, 1}, {-0.5, 0.25, 1}, {0.75, -1, 1}}
+	for _, in := range inputs {
+		out, err := want.Activate(mat.NewDense(1, len(in), in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := got.Compute(in)[0]; math.Abs(v-out.At(0, 0)) > 1e-9 {
+			t.Errorf("inputs %v: output %f, evo %f", in, v, out.At(0, 0))
+		}
+	}
+}
+
+func TestNetwork_Values_stateless(t *testing.T) {
+	// the output feeds back the hidden node, that is evaluated first
+	n, err := Load(strings.NewReader(`{
+		"nodes": [
+			{"id": 0, "kind": "input", "activation": "direct", "layer": 0},
+			{"id": 1, "kind": "hidden", "activation": "direct", "layer": 0.5},
+			{"id": 2, "kind": "output", "activation": "direct", "layer": 1}
+		],
+		"conns": [
+			{"source": 0, "target": 1, "weight": 1, "enabled": true},
+			{"source": 1, "target": 2, "weight": 2, "enabled": true},
+			{"source": 2, "target": 1, "weight": 10, "enabled": true}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if v := n.Values([]float64{1}); v[1] != 1 || v[2] != 2 {
+			t.Errorf("activation %d: unexpected values %v", i, v)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package champion
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Point is a position in the rendered image
+type Point struct {
+	X, Y float64
+}
+
+// Layout places the nodes in a width x height box: every layer is a column, from
+// left to right, and the nodes of a layer are spread vertically following their
+// position. The margin is kept free around the box.
+func (n *Network) Layout(width, height, margin float64) []Point {
+	layers := []float64{}
+	members := map[float64][]int{}
+	for i, node := range n.Nodes {
+		if _, ok := members[node.Layer]; !ok {
+			layers = append(layers, node.Layer)
+		}
+		members[node.Layer] = append(members[node.Layer], i)
+	}
+	sort.Float64s(layers)
+
+	points := make([]Point, len(n.Nodes))
+	for col, layer := range layers {
+		x := width / 2
+		if len(layers) > 1 {
+			x = margin + float64(col)*(width-2*margin)/float64(len(layers)-1)
+		}
+		nodes := members[layer]
+		sort.SliceStable(nodes, func(i, j int) bool { return n.Nodes[nodes[i]].X < n.Nodes[nodes[j]].X })
+		for row, i := range nodes {
+			y := height / 2
+			if len(nodes) > 1 {
+				y = margin + float64(row)*(height-2*margin)/float64(len(nodes)-1)
+			}
+			points[i] = Point{X: x, Y: y}
+		}
+	}
+	return points
+}
+
+// Label returns the name of the node: the sensor name for the inputs, if known
+func (n *Network) Label(i int) string {
+	node := n.Nodes[i]
+	switch node.Kind {
+	case KindInput:
+		for k, in := range n.inputs {
+			if in == i && k < len(n.Inputs) {
+				return n.Inputs[k]
+			}
+		}
+		return fmt.Sprintf("in%d", i)
+	case KindOutput:
+		if len(n.outputs) == 1 {
+			return "jump"
+		}
+		return fmt.Sprintf("out%d", i)
+	}
+	return fmt.Sprintf("h%d", i)
+}
+
+// edgeWidth maps the weight of a connection to a stroke width
+func edgeWidth(weight float64) float64 {
+	return 0.5 + math.Min(math.Abs(weight), 8)/2
+}
+
+func edgeColor(weight float64) string {
+	if weight < 0 {
+		return "#c0392b"
+	}
+	return "#2471a3"
+}
+
+// WriteDOT renders the network as a graphviz graph. Edges are sized by weight,
+// colored by sign and dashed when disabled. The labels are quoted, so any name is safe.
+func (n *Network) WriteDOT(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("digraph network_%d {\n", n.ID)
+	ew.printf("\trankdir=LR;\n\tnode [style=filled, fontname=\"Helvetica\"];\n")
+
+	ranks := map[string][]int{}
+	for i, node := range n.Nodes {
+		color, shape := "#f7dc6f", "ellipse"
+		switch node.Kind {
+		case KindInput:
+			color, shape = "#aed6f1", "box"
+		case KindOutput:
+			color, shape = "#abebc6", "doublecircle"
+		}
+		label := strconv.Quote(fmt.Sprintf("%s\n%s %.2f", n.Label(i), node.Activation, node.Bias))
+		ew.printf("\tn%d [label=%s, shape=%s, fillcolor=\"%s\"];\n", i, label, shape, color)
+		if node.Kind != KindHidden {
+			ranks[node.Kind] = append(ranks[node.Kind], i)
+		}
+	}
+	for _, kind := range []string{KindInput, KindOutput} {
+		if len(ranks[kind]) == 0 {
+			continue
+		}
+		ew.printf("\t{ rank=same;")
+		for _, i := range ranks[kind] {
+			ew.printf(" n%d;", i)
+		}
+		ew.printf(" }\n")
+	}
+
+	for _, c := range n.Conns {
+		style := "solid"
+		if !c.Enabled {
+			style = "dashed"
+		}
+		ew.printf("\tn%d -> n%d [label=\"%.2f\", penwidth=%.2f, color=\"%s\", style=%s];\n",
+			c.Source, c.Target, c.Weight, edgeWidth(c.Weight), edgeColor(c.Weight), style)
+	}
+	ew.printf("}\n")
+	return ew.err
+}
+
+// WriteSVG renders the network as a standalone SVG image, with the same conventions as
+// WriteDOT. The labels are escaped as XML text.
+func (n *Network) WriteSVG(w io.Writer) error {
+	const (
+		width  = 720.0
+		height = 480.0
+		margin = 60.0
+		radius = 14.0
+	)
+	points := n.Layout(width, height, margin)
+
+	ew := &errWriter{w: w}
+	ew.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\" font-family=\"Helvetica\" font-size=\"11\">\n", width, height, width, height)
+	ew.printf("\t<title>network %d</title>\n", n.ID)
+	ew.printf("\t<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+
+	for _, c := range n.Conns {
+		from, to := points[c.Source], points[c.Target]
+		dash := ""
+		if !c.Enabled {
+			dash = " stroke-dasharray=\"6,4\" stroke-opacity=\"0.5\""
+		}
+		if c.Source == c.Target {
+			ew.printf("\t<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%.1f\" fill=\"none\" stroke=\"%s\" stroke-width=\"%.2f\"%s><title>%.3f</title></circle>\n",
+				from.X, from.Y-radius, radius, edgeColor(c.Weight), edgeWidth(c.Weight), dash, c.Weight)
+			continue
+		}
+		ew.printf("\t<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"%s\" stroke-width=\"%.2f\"%s><title>%.3f</title></line>\n",
+			from.X, from.Y, to.X, to.Y, edgeColor(c.Weight), edgeWidth(c.Weight), dash, c.Weight)
+	}
+
+	for i, node := range n.Nodes {
+		p := points[i]
+		color := "#f7dc6f"
+		anchor, dx := "middle", 0.0
+		switch node.Kind {
+		case KindInput:
+			color, anchor, dx = "#aed6f1", "end", -radius-4
+		case KindOutput:
+			color, anchor, dx = "#abebc6", "start", radius+4
+		}
+		ew.printf("\t<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%.1f\" fill=\"%s\" stroke=\"#555\"><title>%s (%s, bias %.3f)</title></circle>\n",
+			p.X, p.Y, radius, color, html.EscapeString(n.Label(i)), html.EscapeString(node.Activation), node.Bias)
+		dy := 4.0
+		if node.Kind == KindHidden {
+			dy = radius + 12
+		}
+		ew.printf("\t<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"%s\">%s</text>\n", p.X+dx, p.Y+dy, anchor, html.EscapeString(n.Label(i)))
+	}
+	ew.printf("</svg>\n")
+	return ew.err
+}
+
+// errWriter keeps the first error, so the renderers can write without checking every call
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
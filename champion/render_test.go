@@ -0,0 +1,61 @@
+package champion
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNetwork_render(t *testing.T) {
+	n, err := Load(strings.NewReader(sample))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	n.Inputs = []string{"altitude", "velocity"}
+
+	buf := new(bytes.Buffer)
+	if err := n.WriteDOT(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	dot := buf.String()
+	for _, expected := range []string{"digraph network_42", "label=\"altitude\\n", "label=\"jump\\n", "style=dashed", "n2 -> n3"} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("%q not found in the DOT output:\n%s", expected, dot)
+		}
+	}
+
+	buf.Reset()
+	if err := n.WriteSVG(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	svg := buf.String()
+	if strings.Count(svg, "<line") != len(n.Conns) || strings.Count(svg, "stroke-dasharray") != 1 || !strings.Contains(svg, ">velocity</text>") {
+		t.Errorf("unexpected SVG output:\n%s", svg)
+	}
+
+	n.Inputs = []string{`say "hi"`, "a<b & c"}
+	buf.Reset()
+	if err := n.WriteDOT(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(buf.String(), `label="say \"hi\"\n`) {
+		t.Errorf("unescaped DOT label:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := n.WriteSVG(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	if svg := buf.String(); !strings.Contains(svg, ">a&lt;b &amp; c</text>") || strings.Contains(svg, "a<b") {
+		t.Errorf("unescaped SVG label:\n%s", svg)
+	}
+
+	points := n.Layout(100, 100, 10)
+	if points[0].X != 10 || points[2].X != 50 || points[3].X != 90 || points[0].Y != 10 || points[1].Y != 90 {
+		t.Errorf("unexpected layout: %+v", points)
+	}
+}
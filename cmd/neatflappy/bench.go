@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/kpacha/neatflappy"
+)
+
+// bench flies a genome over the benchmark levels in the headless game
+func bench(args []string) {
+	f := flag.NewFlagSet("bench", flag.ExitOnError)
+	var (
+		genome = addGenomeFlags(f)
+		cpath  = addConfigFlag(f)
+		levels = f.String("levels", "", "levels to fly, comma separated (the benchmark suite by default)")
+		seed   = f.Int64("seed", 0, "seed for the random pipes of the advanced levels (0 keeps the default ones)")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s bench [flags]\n\nFlies a stored genome or an exported network over the benchmark levels, without\nthe game window, and reports the outcome of every flight.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	phenome, _, err := genome.phenome(*cpath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	b := neatflappy.Benchmark{}
+	if *levels != "" {
+		numbers := []int{}
+		for _, s := range splitList(*levels) {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				log.Fatalf("bad level %q", s)
+			}
+			numbers = append(numbers, n)
+		}
+		b.Levels = func() []neatflappy.Level {
+			res := []neatflappy.Level{}
+			for _, n := range numbers {
+				res = append(res, neatflappy.NewLevel(n, *seed))
+			}
+			return res
+		}
+	}
+
+	outcomes := b.Fly(neatflappy.NewPhenomeJumper(phenome))
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "level\tscore\tdistance\tpipes\tjumps\tticks\tstatus\t")
+	for _, o := range outcomes {
+		fmt.Fprintf(w, "%s\t%.1f\t%d\t%d\t%d\t%d\t%s\t\n", o.Level, o.Score, o.Distance, o.Pipes, o.Jumps, o.Ticks, o.Status())
+	}
+	w.Flush()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/kpacha/neatflappy"
+)
+
+// correct lets a champion fly while the player forces the jumps it misses. The
+// visited states are logged with the decisions of the player, so the trainer can
+// learn from the states the plain human recordings never reach.
+func correct(args []string) {
+	f := flag.NewFlagSet("correct", flag.ExitOnError)
+	var (
+		genome      = addGenomeFlags(f)
+		cpath       = addConfigFlag(f)
+		out         = f.String("out", "./corrections.txt", "path of the trace log")
+		appendLog   = f.Bool("append", false, "append the sessions to the trace log instead of truncating it")
+		sessions    = f.Int("sessions", 1, "number of flights to correct, one session each")
+		level       = f.Int("level", 1, "level to fly")
+		seed        = f.Int64("seed", 0, "seed for the random pipes of the advanced levels (0 keeps the default ones)")
+		speedFactor = addSpeedFlag(f)
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s correct [flags]\n\nFlies a stored genome or an exported network while the player can force a jump at any moment.\nEvery state is logged with the decision of the player, ready to train again on the union of the logs.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	phenome, _, err := genome.phenome(*cpath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	agent := neatflappy.NewPhenomeJumper(phenome)
+
+	traces, err := neatflappy.CreateTraceLog(*out, *appendLog, neatflappy.NewTraceHeader("correction", *level, *seed))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer traces.Close()
+
+	g := neatflappy.NewGame(*speedFactor, *sessions, 1)
+	// the trace log can not take back the ticks already written
+	g.RewindSeconds = 0
+	g.SetLevel(neatflappy.NewLevel(*level, *seed))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		defer cancel()
+		newJumper := func(w io.Writer, episode int) neatflappy.LogJumper {
+			return &neatflappy.CorrectionJumper{Agent: agent, Out: w, Episode: episode}
+		}
+		if err := traces.Record(ctx, g.Task, *sessions, newJumper); err != nil {
+			log.Println("recording the sessions:", err.Error())
+			return
+		}
+		time.Sleep(2 * time.Second)
+	}()
+
+	runGame(ctx, g, *speedFactor, fmt.Sprintf("Flappy Gopher (correcting genome %d)", phenome.ID))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/klokare/evo"
+	"github.com/klokare/evo/config"
+	"github.com/klokare/evo/config/source"
+	"github.com/klokare/evo/neat"
+)
+
+// newExperiment builds a NEAT experiment configured by the environment and the
+// configuration file at cpath, in that order of precedence. The subcommands parse
+// flag sets of their own, so the NEAT parameters are not read from the flags.
+func newExperiment(cpath string) (*neat.Experiment, error) {
+	src, err := source.NewJSONFromFile(cpath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.Configurer{Source: source.Multi([]config.Source{
+		source.Environment{}, // Check environment variables first
+		src,                  // Then consult the configuration file
+	})}
+	return neat.NewExperiment(cfg), nil
+}
+
+// addConfigFlag adds the -config flag with the configuration file of the experiment,
+// evolving the genomes or translating the stored ones
+func addConfigFlag(f *flag.FlagSet) *string {
+	return f.String("config", "neatflappy.json", "path to the configuration file of the experiment")
+}
+
+// withLimits stops the experiment after the iterations or as soon as a genome solves it
+func withLimits(exp *neat.Experiment, iterations int) (context.Context, context.CancelFunc) {
+	// Run the experiment for a set number of iterations
+	ctx, stopIterations, cb := evo.WithIterations(context.Background(), iterations)
+	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: cb})
+
+	// Stop the experiment if there is a solution
+	ctx, stopSolution, cb := evo.WithSolution(ctx)
+	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: cb})
+
+	return ctx, func() {
+		stopSolution()
+		stopIterations()
+	}
+}
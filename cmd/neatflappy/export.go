@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// export writes a stored genome as a portable JSON network
+func export(args []string) {
+	f := flag.NewFlagSet("export", flag.ExitOnError)
+	var (
+		genome = addGenomeFlags(f)
+		out    = f.String("out", "", "write the network to this file instead of stdout")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s export [flags]\n\nWrites a stored genome as a portable JSON network.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	net, err := genome.network()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	if err := writeOutput(*out, net.Write); err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// writeOutput writes to the file at path, or stdout if the path is empty, and closes
+// the file before reporting the first error, so no fatal exit skips the close
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
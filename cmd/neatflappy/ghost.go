@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kpacha/neatflappy"
+)
+
+// ghost flies alongside a translucent ghost replaying a recorded flight
+func ghost(args []string) {
+	f := flag.NewFlagSet("ghost", flag.ExitOnError)
+	var (
+		genome      = addGenomeFlags(f)
+		cpath       = addConfigFlag(f)
+		replayPath  = f.String("replay", "", "replay file to race against")
+		tracePath   = f.String("trace", "", "trace log to race against, as recorded by the record command")
+		session     = f.Int("session", 0, "session of the trace log to race against (0 selects the first one)")
+		level       = f.Int("level", 0, "level to fly (0 uses the level of the replay)")
+		seed        = f.Int64("seed", 0, "seed for the random pipes of the advanced levels (0 uses the seed of the replay)")
+		speedFactor = addSpeedFlag(f)
+		rounds      = f.Int("rounds", 1, "number of rounds")
+		record      = f.String("record", "", "save the best flight of the player as a replay file")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s ghost [flags]\n\nRaces against the ghost of a recorded flight. The player is human unless\na genome or an exported network is selected.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	replay, err := loadReplay(*replayPath, *tracePath, *session)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if *level == 0 {
+		*level = replay.Level
+	}
+	if *level == 0 {
+		*level = 1
+	}
+	if *seed == 0 {
+		*seed = replay.Seed
+	}
+
+	player := neatflappy.Jumper(new(neatflappy.InteractiveJumper))
+	name := "human"
+	if genome.selected() {
+		phenome, _, err := genome.phenome(*cpath)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		player = neatflappy.NewPhenomeJumper(phenome)
+		name = fmt.Sprintf("genome-%d", phenome.ID)
+	}
+
+	g := neatflappy.NewGame(*speedFactor, *rounds, 1)
+	g.SetLevel(neatflappy.NewLevel(*level, *seed))
+	g.AddGhost(replay.Name, replay.Jumper())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		var best *neatflappy.Recorder
+		bestScore := -1.0
+		for round := 1; round <= *rounds; round++ {
+			recorder := &neatflappy.Recorder{
+				Jumper: player,
+				Replay: neatflappy.Replay{Name: name, Level: *level, Seed: *seed},
+			}
+			task := neatflappy.Task{
+				Jumper:  recorder,
+				Fitness: make(chan float64),
+				Name:    name,
+			}
+			g.Task <- task
+			score := <-task.Fitness
+			log.Printf("round %d: %s scored %.2f in %d ticks, the ghost flew %d ticks", round, name, score, recorder.Replay.Ticks, replay.Ticks)
+			if score > bestScore {
+				best, bestScore = recorder, score
+			}
+		}
+		if *record != "" && best != nil {
+			if err := saveReplay(*record, best.Replay); err != nil {
+				log.Println("saving the replay:", err.Error())
+			}
+		}
+		time.Sleep(2 * time.Second)
+		cancel()
+	}()
+
+	runGame(ctx, g, *speedFactor, "Flappy Gopher (ghost race)")
+}
+
+// loadReplay reads a replay file or, if no replay is given, builds it from the trace log
+func loadReplay(replayPath, tracePath string, session int) (neatflappy.Replay, error) {
+	if replayPath == "" && tracePath == "" {
+		return neatflappy.Replay{}, fmt.Errorf("a replay or a trace log is required")
+	}
+	path := replayPath
+	if path == "" {
+		path = tracePath
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return neatflappy.Replay{}, err
+	}
+	defer file.Close()
+
+	if replayPath != "" {
+		return neatflappy.LoadReplay(file)
+	}
+	return neatflappy.ReplayFromLog(file, filepath.Base(tracePath), session)
+}
+
+func saveReplay(path string, replay neatflappy.Replay) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := replay.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/kpacha/neatflappy"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// hallOfFame prints or exports the leaderboard of the best genomes across every run
+func hallOfFame(args []string) {
+	f := flag.NewFlagSet("hof", flag.ExitOnError)
+	var (
+		cpath   = addConfigFlag(f)
+		stores  = addStoreFlags(f)
+		size    = f.Int("size", store.DefaultHallOfFameSize, "number of genomes in the hall of fame")
+		rebuild = f.Bool("rebuild", false, "benchmark again the entries and the best genome of every run")
+		format  = f.String("format", "text", "output format: text or json")
+		out     = f.String("out", "", "write the leaderboard to this file instead of stdout")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s hof [flags]\n\nPrints the top genomes across every run, ranked by the benchmark levels.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	entries, err := loadHallOfFame(stores, *cpath, *size, *rebuild)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	err = writeOutput(*out, func(w io.Writer) error {
+		switch *format {
+		case "json":
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "\t")
+			return enc.Encode(entries)
+		case "text":
+			return printHallOfFame(w, entries)
+		}
+		return fmt.Errorf("unknown format: %s", *format)
+	})
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// loadHallOfFame returns the entries of the hall of fame, benchmarked again if rebuild is
+// set, closing the store before returning
+func loadHallOfFame(stores storeFlags, cpath string, size int, rebuild bool) ([]store.Fame, error) {
+	db, err := stores.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if !rebuild {
+		return db.HallOfFame()
+	}
+	exp, err := newExperiment(cpath)
+	if err != nil {
+		return nil, err
+	}
+	benchmark := neatflappy.Benchmark{Translator: exp.Translator}
+	h := store.HallOfFame{Store: db, Size: size, Benchmark: benchmark.Scores}
+	return h.Rebuild()
+}
+
+func printHallOfFame(w io.Writer, entries []store.Fame) error {
+	if _, err := fmt.Fprintf(w, "%4s %6s %8s %14s %8s %10s  %s\n", "rank", "run", "genome", "score", "species", "complexity", "levels (distance/pipes)"); err != nil {
+		return err
+	}
+	for i, e := range entries {
+		levels := ""
+		for _, l := range e.Levels {
+			levels += fmt.Sprintf("%s: %d/%d ", l.Level, l.Distance, l.Pipes)
+		}
+		if _, err := fmt.Fprintf(w, "%4d %6d %8d %14.2f %8d %10d  %s\n", i+1, e.Run, e.Genome.ID, e.Score, e.Genome.Species, e.Genome.Complexity(), levels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
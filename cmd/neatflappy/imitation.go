@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/klokare/evo"
+	"github.com/klokare/evo/neat"
+	"github.com/kpacha/neatflappy"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// imitationFlags are the flags selecting the traces and the scoring of the imitation training
+type imitationFlags struct {
+	training  *string
+	split     *string
+	holdout   *float64
+	splitSeed *int64
+	metric    *string
+	tolerance *int
+	fly       *bool
+	flyEvery  *int
+}
+
+func addImitationFlags(f *flag.FlagSet) imitationFlags {
+	return imitationFlags{
+		training:  f.String("training", "log.txt", "paths to the training data files, comma separated"),
+		split:     f.String("split", neatflappy.SplitEpisode, "holdout of the validation traces: none, random, episode or session (none when the traces can not be split)"),
+		holdout:   f.Float64("holdout", 0.2, "fraction of the traces held out for validation"),
+		splitSeed: f.Int64("split-seed", 1, "seed for the holdout selection"),
+		metric:    f.String("metric", neatflappy.MetricAccuracy, "metric driving the fitness: accuracy, balanced or f1 (balanced and f1 weigh the rare jumps)"),
+		tolerance: f.Int("tolerance", 0, "ticks a jump can be away from the recorded one and still count as right"),
+		fly:       f.Bool("fly", true, "fly the best imitation genome over the benchmark levels at the end"),
+		flyEvery:  f.Int("fly-every", 0, "also fly the best imitation genome every this number of generations (0 disables it)"),
+	}
+}
+
+// evaluator loads the traces and builds the imitation evaluator. The translator
+// builds the networks flown in the headless game.
+func (i imitationFlags) evaluator(translator evo.Translator) (neatflappy.TrainEvaluator, error) {
+	traces, err := neatflappy.LoadTraceFiles(strings.Split(*i.training, ",")...)
+	if err != nil {
+		return neatflappy.TrainEvaluator{}, err
+	}
+	data, err := neatflappy.NewDataset(traces)
+	if err != nil {
+		return neatflappy.TrainEvaluator{}, err
+	}
+	log.Printf("%d traces loaded from %s", data.Len(), *i.training)
+
+	train, validation, err := data.Split(*i.split, *i.holdout, *i.splitSeed)
+	if err == neatflappy.ErrTooFewGroups {
+		// a single life can not be held out, as with the default split of a short log
+		log.Printf("warning: the traces can not be split by %s, training without validation", *i.split)
+		train, validation, err = data, nil, nil
+	}
+	if err != nil {
+		return neatflappy.TrainEvaluator{}, err
+	}
+	if validation != nil {
+		log.Printf("%d traces for training, %d held out for validation", train.Len(), validation.Len())
+	}
+
+	if _, ok := neatflappy.Metrics[*i.metric]; !ok {
+		return neatflappy.TrainEvaluator{}, fmt.Errorf("unknown metric %q", *i.metric)
+	}
+	evaluator := neatflappy.NewTrainEvaluator(train, validation)
+	evaluator.Metric = *i.metric
+	evaluator.Tolerance = *i.tolerance
+	if *i.fly || *i.flyEvery > 0 {
+		evaluator.Flights = &neatflappy.Benchmark{Translator: translator}
+		evaluator.FlightEvery = *i.flyEvery
+	}
+	return evaluator, nil
+}
+
+// imitate runs the experiment against the recorded traces, without the game window,
+// and returns the last population
+func imitate(exp *neat.Experiment, evaluator neatflappy.TrainEvaluator, iterations int) (evo.Population, error) {
+	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: evaluator.ReportValidation})
+	exp.AddSubscription(evo.Subscription{Event: evo.Completed, Callback: evaluator.FlyBest})
+
+	ctx, cancel := withLimits(exp, iterations)
+	defer cancel()
+
+	pop, err := evo.Run(ctx, exp, evaluator)
+	if err == context.Canceled {
+		err = nil
+	}
+	return pop, err
+}
+
+// imitateCommand evolves the genomes imitating the recorded traces, storing the best of every generation
+func imitateCommand(args []string) {
+	f := flag.NewFlagSet("imitate", flag.ExitOnError)
+	var (
+		iter      = f.Int("iterations", 100, "number of iterations for experiment")
+		cpath     = addConfigFlag(f)
+		imitation = addImitationFlags(f)
+		stores    = addStoreFlags(f)
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s imitate [flags]\n\nEvolves the genomes imitating the recorded traces, without the game window.\nThe best genome of every generation is stored.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	exp, err := newExperiment(*cpath)
+	if err != nil {
+		log.Fatalf("%+v\n", err)
+	}
+	evaluator, err := imitation.evaluator(exp.Translator)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	db, err := stores.open()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer db.Close()
+	runID, err := db.NewRun()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	log.Println("starting run", runID)
+	storeWatcher := store.Evo{Store: db, Run: runID}
+	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: storeWatcher.StoreBest})
+
+	if _, err := imitate(exp, evaluator, *iter); err != nil {
+		log.Fatalf("%+v\n", err)
+	}
+}
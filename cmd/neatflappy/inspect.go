@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/kpacha/neatflappy/champion"
+)
+
+// inspect describes the network of a stored genome or an exported file
+func inspect(args []string) {
+	f := flag.NewFlagSet("inspect", flag.ExitOnError)
+	var (
+		genome  = addGenomeFlags(f)
+		details = f.Bool("details", false, "list every node and connection")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s inspect [flags]\n\nDescribes the network of a stored genome or an exported file: its origin, its\nsize and, with -details, every node and connection.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	net, err := genome.network()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	kinds := map[string]int{}
+	for _, n := range net.Nodes {
+		kinds[n.Kind]++
+	}
+	enabled := 0
+	for _, c := range net.Conns {
+		if c.Enabled {
+			enabled++
+		}
+	}
+	fmt.Printf("genome:  %d (run %d, species %d)\n", net.ID, net.Run, net.Species)
+	fmt.Printf("fitness: %f\n", net.Fitness)
+	fmt.Printf("nodes:   %d inputs, %d hidden, %d outputs\n", kinds[champion.KindInput], kinds[champion.KindHidden], kinds[champion.KindOutput])
+	fmt.Printf("conns:   %d enabled, %d disabled\n", enabled, len(net.Conns)-enabled)
+	if !*details {
+		return
+	}
+
+	fmt.Println("\nnodes:")
+	for i, n := range net.Nodes {
+		fmt.Printf("  %3d %-10s %-7s %-20s bias %8.4f layer %.2f\n", i, net.Label(i), n.Kind, n.Activation, n.Bias, n.Layer)
+	}
+	fmt.Println("\nconns:")
+	for _, c := range net.Conns {
+		status := ""
+		if !c.Enabled {
+			status = " (disabled)"
+		}
+		fmt.Printf("  %-10s -> %-10s %8.4f%s\n", net.Label(c.Source), net.Label(c.Target), c.Weight, status)
+	}
+}
@@ -3,78 +3,207 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"math/rand"
+	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/klokare/evo"
-	"github.com/klokare/evo/config"
-	"github.com/klokare/evo/config/source"
 	"github.com/klokare/evo/example"
 	"github.com/klokare/evo/neat"
 	"github.com/kpacha/neatflappy"
-	"github.com/kpacha/neatflappy/bolt"
+	"github.com/kpacha/neatflappy/store"
 )
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
+// command is a subcommand of the CLI
+type command struct {
+	run     func(args []string)
+	summary string
+}
+
+// commands are the subcommands, selected by the first argument. Without a
+// subcommand, or starting with a flag, the arguments are passed to train.
+var commands = map[string]command{
+	"train":    {train, "evolve the genomes flying them in the game window"},
+	"imitate":  {imitateCommand, "evolve the genomes imitating recorded traces"},
+	"pipeline": {pipeline, "imitate recorded traces, then fine-tune the best genomes in the game"},
+	"record":   {recordCommand, "record the flights of a human player as a trace log"},
+	"correct":  {correct, "let a genome fly while the player corrects its jumps"},
+	"play":     {play, "fly a stored genome or an exported network in the game window"},
+	"replay":   {replay, "watch a recorded flight again"},
+	"ghost":    {ghost, "race against the ghost of a recorded flight"},
+	"race":     {race, "race against the champions of the hall of fame"},
+	"inspect":  {inspect, "describe a stored genome or an exported network"},
+	"bench":    {bench, "fly a genome over the benchmark levels and report the outcomes"},
+	"export":   {export, "write a stored genome as a portable JSON network"},
+	"render":   {render, "draw the topology of a genome as SVG or DOT"},
+	"hof":      {hallOfFame, "print the hall of fame of the best genomes across every run"},
+	"traces":   {traces, "curate the trace logs: stats, merge, dedupe, filter, split and convert"},
+}
+
 func main() {
-	// Parse the command-line flags
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		train(os.Args[1:])
+		return
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage(os.Stderr)
+		if os.Args[1] == "help" {
+			return
+		}
+		os.Exit(2)
+	}
+	cmd.run(os.Args[2:])
+}
+
+// usage lists the subcommands
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command.\n", os.Args[0])
+}
+
+// train evolves the genomes flying them in the game window
+func train(args []string) {
+	f := flag.NewFlagSet("train", flag.ExitOnError)
 	var (
-		iter        = flag.Int("iterations", 150, "number of iterations for experiment")
-		speedFactor = flag.Int("speed", 100, "speed factor")
-		cpath       = flag.String("config", "neatflappy.json", "path to the configuration file")
+		iter        = f.Int("iterations", 150, "number of iterations for experiment")
+		speedFactor = addSpeedFlag(f)
+		steps       = f.Int("steps", 1, "ticks simulated on every frame of the game window")
+		turbo       = f.Bool("turbo", false, "simulate as many ticks as fit in every frame, rendering only the last one")
+		rewind      = f.Int("rewind", 5, "seconds of flight the R key rewinds in the game window (0 disables it)")
+		cpath       = addConfigFlag(f)
+		stores      = addStoreFlags(f)
+		checkpoint  = f.Bool("checkpoint", false, "store the whole population every generation")
+		hofSize     = f.Int("hof", 0, "size of the hall of fame fed with the best genome whenever the run improves, benchmarking it (0 disables it)")
+		headless    = f.Bool("headless", false, "fly the generations without rendering, at simulation speed")
+		watchEvery  = f.Int("watch-every", 0, "with -headless, show the champion in the game window every this number of generations (0 never opens it)")
+		seed        = f.Int64("seed", 0, "seed for the random pipes of the advanced levels (0 draws one for the run)")
 	)
-	flag.Parse()
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s train [flags]\n\nEvolves the genomes flying every generation in the game window. The best genome\nof every generation is stored.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
 
-	src, err := source.NewJSONFromFile(*cpath)
+	exp, err := newExperiment(*cpath)
 	if err != nil {
 		log.Fatalf("%+v\n", err)
 	}
-	cfg := config.Configurer{Source: source.Multi([]config.Source{
-		source.Flag{},        // Check flags  first
-		source.Environment{}, // Then check environment variables
-		src,                  // Lastly, consult the configuration file
-	})}
-	exp := neat.NewExperiment(cfg)
-	exp.Searcher = neatflappy.Searcher{}
 
-	client, err := bolt.New()
+	db, err := stores.open()
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	defer client.Close()
+	defer db.Close()
+
+	evolveInGame(exp, exp, db, gameOptions{
+		iterations:  *iter,
+		speedFactor: *speedFactor,
+		steps:       *steps,
+		turbo:       *turbo,
+		rewind:      *rewind,
+		checkpoint:  *checkpoint,
+		hofSize:     *hofSize,
+		title:       "Flappy Gopher (NEAT edition)",
+		headless:    *headless,
+		watchEvery:  *watchEvery,
+		seed:        *seed,
+	})
+}
+
+// gameOptions configure the training with the game fitness
+type gameOptions struct {
+	iterations  int
+	speedFactor int
+	// steps and turbo set the ticks simulated on every frame of the game window
+	// and rewind the seconds of flight kept for rewinding it
+	steps      int
+	turbo      bool
+	rewind     int
+	checkpoint bool
+	hofSize    int
+	title      string
+	// headless flies the generations without rendering, showing the champion
+	// in the game window every watchEvery generations, if set
+	headless   bool
+	watchEvery int
+	// seed draws the random pipes of the advanced levels; 0 draws one for the run
+	seed int64
+}
+
+// configure applies the simulation settings to the game window
+func (o gameOptions) configure(g *neatflappy.Game) {
+	if o.steps > 0 {
+		g.StepsPerFrame = o.steps
+	}
+	g.Turbo = o.turbo
+	g.RewindSeconds = o.rewind
+}
 
-	boltWatcher := bolt.Evo{Client: client}
+// evolveInGame runs the experiment with the game fitness, flying every generation in
+// the game window or, if headless, in the simulation. The run uses the populator of
+// the experiment unless another experiment wrapping it is given as run.
+func evolveInGame(exp *neat.Experiment, run evo.Experiment, db store.Store, opts gameOptions) {
+	exp.Searcher = neatflappy.Searcher{}
 
-	g := neatflappy.NewGame(*speedFactor, *iter, exp.Populator.PopulationSize)
+	runID, err := db.NewRun()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if opts.seed == 0 {
+		opts.seed = neatflappy.NewRunSeed()
+	}
+	log.Printf("starting run %d with the level seed %d", runID, opts.seed)
 
-	evaluator := neatflappy.Evaluator{
-		Task:       g.Task,
-		Population: g.NextPopulation,
+	storeWatcher := store.Evo{Store: db, Run: runID}
+	if opts.hofSize > 0 {
+		benchmark := neatflappy.Benchmark{Translator: exp.Translator}
+		storeWatcher.HallOfFame = &store.HallOfFame{Store: db, Size: opts.hofSize, Benchmark: benchmark.Scores}
 	}
 
 	exp.AddSubscription(evo.Subscription{Event: evo.Completed, Callback: example.ShowBest})
-	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: boltWatcher.StoreBest})
-	exp.AddSubscription(evo.Subscription{Event: evo.Decoded, Callback: evaluator.PreSearch})
-	// exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: evaluator.PostSearch})
-	// Run the experiment for a set number of iterations
-	ctx, fn, cb := evo.WithIterations(context.Background(), *iter)
-	defer fn() // ensure the context cancels
-	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: cb})
+	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: storeWatcher.StoreBest})
+	if opts.checkpoint {
+		exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: storeWatcher.Checkpoint})
+	}
+	ctx, cancel := withLimits(exp, opts.iterations)
+	defer cancel()
+
+	if opts.headless {
+		evolveHeadless(ctx, cancel, exp, run, opts)
+		return
+	}
 
-	// Stop the experiment if there is a solution
-	ctx, fn, cb = evo.WithSolution(ctx)
-	defer fn() // ensure the context cancels
-	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: cb})
+	g := neatflappy.NewGame(opts.speedFactor, opts.iterations, exp.Populator.PopulationSize)
+	g.Seed = opts.seed
+	opts.configure(g)
+
+	evaluator := neatflappy.NewEvaluator(g.Task, g.NextPopulation)
+	exp.AddSubscription(evo.Subscription{Event: evo.Decoded, Callback: evaluator.PreSearch})
+	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: evaluator.PostSearch})
 
 	go func() {
 		// Execute the experiment
-		if _, err = evo.Run(ctx, exp, evaluator); err != nil {
+		if _, err = evo.Run(ctx, run, evaluator); err != nil {
 			log.Fatalf("%+v\n", err)
 		}
 	}()
@@ -85,8 +214,85 @@ func main() {
 		ebiten.SetFullscreen(true)
 	}
 	ebiten.SetRunnableInBackground(true)
-	ebiten.SetMaxTPS(60 * *speedFactor / 100)
-	if err := ebiten.Run(g.Update(ctx), neatflappy.ScreenWidth, neatflappy.ScreenHeight, 1, "Flappy Gopher (NEAT edition)"); err != nil {
+	ebiten.SetMaxTPS(60 * opts.speedFactor / 100)
+	if err := ebiten.Run(g.Update(ctx), neatflappy.ScreenWidth, neatflappy.ScreenHeight, 1, opts.title); err != nil {
 		panic(err)
 	}
 }
+
+// evolveHeadless runs the experiment in the simulation, writing the progress to stdout.
+// If watchEvery is set, the champion flies in the game window every watchEvery generations.
+func evolveHeadless(ctx context.Context, cancel context.CancelFunc, exp *neat.Experiment, run evo.Experiment, opts gameOptions) {
+	h := neatflappy.NewHeadless(exp.Populator.PopulationSize)
+	h.Seed = opts.seed
+	go h.Run(ctx)
+	evaluator := neatflappy.Evaluator{Task: h.Task}
+	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: printProgress})
+
+	if opts.watchEvery <= 0 {
+		if _, err := evo.Run(ctx, run, evaluator); err != nil {
+			log.Fatalf("%+v\n", err)
+		}
+		return
+	}
+
+	watch := neatflappy.NewGame(opts.speedFactor, opts.iterations, 1)
+	opts.configure(watch)
+	// every champion flies the level of its generation, carried by its task
+	watch.SetLevel(neatflappy.NewLevel(1, 0))
+	exp.AddSubscription(evo.Subscription{Event: evo.Evaluated, Callback: watchChampion(watch, h, exp.Translator, opts.watchEvery)})
+	go func() {
+		defer cancel()
+		if _, err := evo.Run(ctx, run, evaluator); err != nil {
+			log.Fatalf("%+v\n", err)
+		}
+	}()
+	runGame(ctx, watch, opts.speedFactor, opts.title)
+}
+
+// printProgress writes the summary of the generation to stdout
+func printProgress(pop evo.Population) error {
+	s, err := store.Summarize(0, pop)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("generation %d: best %d, fitness %.2f, mean %.2f, species %d, complexity %d, solved %t\n",
+		s.Generation, s.Best, s.Fitness, s.MeanFitness, s.Species, s.Complexity, s.Solved)
+	return nil
+}
+
+// watchChampion sends the best genome to the game window every n generations, to fly
+// the level of the generation. The champion is skipped if the window is still busy.
+func watchChampion(g *neatflappy.Game, h *neatflappy.Headless, translator evo.Translator, n int) evo.Callback {
+	return func(pop evo.Population) error {
+		if pop.Generation%n != 0 {
+			return nil
+		}
+		best, err := store.Best(pop.Genomes)
+		if err != nil {
+			return err
+		}
+		net, err := translator.Translate(best.Decoded)
+		if err != nil {
+			return err
+		}
+		task := neatflappy.Task{
+			ID:        best.ID,
+			Jumper:    neatflappy.NewPhenomeJumper(evo.Phenome{ID: best.ID, Traits: best.Traits, Network: net}),
+			Fitness:   make(chan float64),
+			Name:      fmt.Sprintf("generation %d", pop.Generation),
+			Level:     h.CurrentLevel(),
+			Species:   best.Species,
+			Substrate: best.Decoded,
+		}
+		select {
+		case g.Task <- task:
+			go func() {
+				log.Printf("champion of generation %d: fitness %f in the window", pop.Generation, <-task.Fitness)
+			}()
+		default:
+			log.Printf("the window is busy, skipping the champion of generation %d", pop.Generation)
+		}
+		return nil
+	}
+}
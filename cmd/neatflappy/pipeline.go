@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/klokare/evo"
+	"github.com/klokare/evo/neat"
+	"github.com/kpacha/neatflappy"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// seededExperiment is the experiment starting from the population of the seeded populator
+type seededExperiment struct {
+	*neat.Experiment
+	populator evo.Populator
+}
+
+func (e seededExperiment) Populate() (evo.Population, error) {
+	return e.populator.Populate()
+}
+
+// pipeline pretrains the genomes imitating the recorded traces and fine-tunes the
+// best of them with the game fitness
+func pipeline(args []string) {
+	f := flag.NewFlagSet("pipeline", flag.ExitOnError)
+	var (
+		cpath       = addConfigFlag(f)
+		imitation   = addImitationFlags(f)
+		pretrain    = f.Int("pretrain-iterations", 100, "number of iterations of the imitation training")
+		seeds       = f.Int("seeds", 5, "number of imitation genomes seeding the game training")
+		iter        = f.Int("iterations", 150, "number of iterations of the game training")
+		speedFactor = addSpeedFlag(f)
+		steps       = f.Int("steps", 1, "ticks simulated on every frame of the game window")
+		turbo       = f.Bool("turbo", false, "simulate as many ticks as fit in every frame, rendering only the last one")
+		rewind      = f.Int("rewind", 5, "seconds of flight the R key rewinds in the game window (0 disables it)")
+		stores      = addStoreFlags(f)
+		checkpoint  = f.Bool("checkpoint", false, "store the whole population every generation")
+		hofSize     = f.Int("hof", 0, "size of the hall of fame fed with the best genome whenever the run improves, benchmarking it (0 disables it)")
+		headless    = f.Bool("headless", false, "fly the generations of the game training without rendering, at simulation speed")
+		watchEvery  = f.Int("watch-every", 0, "with -headless, show the champion in the game window every this number of generations (0 never opens it)")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s pipeline [flags]\n\nTrains the genomes imitating the recorded traces, then seeds the game training with the best of them.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	pre, err := newExperiment(*cpath)
+	if err != nil {
+		log.Fatalf("%+v\n", err)
+	}
+	evaluator, err := imitation.evaluator(pre.Translator)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	log.Println("imitation training")
+	pop, err := imitate(pre, evaluator, *pretrain)
+	if err != nil {
+		log.Fatalf("%+v\n", err)
+	}
+	top := store.Top(pop.Genomes, *seeds)
+	if len(top) == 0 {
+		log.Fatal(neatflappy.ErrNoSeeds.Error())
+	}
+	for i, g := range top {
+		log.Printf("seed %d: genome %d, fitness %f, solved %t", i+1, g.ID, g.Fitness, g.Solved)
+	}
+
+	exp, err := newExperiment(*cpath)
+	if err != nil {
+		log.Fatalf("%+v\n", err)
+	}
+	db, err := stores.open()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	log.Println("game training")
+	run := seededExperiment{
+		Experiment: exp,
+		populator:  neatflappy.SeededPopulator{Populator: exp.Populator, Mutator: exp.Mutator, Seeds: top},
+	}
+	evolveInGame(exp, run, db, gameOptions{
+		iterations:  *iter,
+		speedFactor: *speedFactor,
+		steps:       *steps,
+		turbo:       *turbo,
+		rewind:      *rewind,
+		checkpoint:  *checkpoint,
+		hofSize:     *hofSize,
+		title:       "Flappy Gopher (fine-tuning)",
+		headless:    *headless,
+		watchEvery:  *watchEvery,
+	})
+}
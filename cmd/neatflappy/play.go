@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/kpacha/neatflappy"
+)
+
+// play flies a stored champion or an exported network in the game window
+func play(args []string) {
+	f := flag.NewFlagSet("play", flag.ExitOnError)
+	var (
+		genome      = addGenomeFlags(f)
+		cpath       = addConfigFlag(f)
+		level       = f.Int("level", 1, "level to fly")
+		seed        = f.Int64("seed", 0, "seed for the random pipes of the advanced levels (0 keeps the default ones)")
+		speedFactor = addSpeedFlag(f)
+		repeat      = f.Int("repeat", 1, "number of flights")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s play [flags]\n\nFlies a stored genome or an exported network in the game window.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	phenome, substrate, err := genome.phenome(*cpath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	jumper := neatflappy.NewPhenomeJumper(phenome)
+
+	g := neatflappy.NewGame(*speedFactor, *repeat, 1)
+	g.SetLevel(neatflappy.NewLevel(*level, *seed))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		for i := 0; i < *repeat; i++ {
+			task := neatflappy.Task{
+				ID:        phenome.ID,
+				Jumper:    jumper,
+				Fitness:   make(chan float64),
+				Substrate: substrate,
+			}
+			g.Task <- task
+			log.Printf("flight %d of genome %d: fitness %f", i+1, phenome.ID, <-task.Fitness)
+		}
+		time.Sleep(2 * time.Second)
+		cancel()
+	}()
+
+	runGame(ctx, g, *speedFactor, fmt.Sprintf("Flappy Gopher (genome %d)", phenome.ID))
+}
+
+// runGame opens the game window until the context is canceled or the window is closed
+func runGame(ctx context.Context, g *neatflappy.Game, speedFactor int, title string) {
+	ebiten.SetMaxTPS(60 * speedFactor / 100)
+	if err := ebiten.Run(g.Update(ctx), neatflappy.ScreenWidth, neatflappy.ScreenHeight, 1, title); err != nil && err != context.Canceled {
+		log.Fatal(err.Error())
+	}
+}
+
+// speedFlag is the speed factor of the game window, in percent of the normal one
+type speedFlag int
+
+func (s *speedFlag) String() string { return strconv.Itoa(int(*s)) }
+
+// Set rejects the factors too slow to simulate a tick per second
+func (s *speedFlag) Set(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	if 60*n/100 < 1 {
+		return errors.New("the speed factor must be 2 or more")
+	}
+	*s = speedFlag(n)
+	return nil
+}
+
+// addSpeedFlag adds the -speed flag of the commands opening the game window
+func addSpeedFlag(f *flag.FlagSet) *int {
+	speed := speedFlag(100)
+	f.Var(&speed, "speed", "speed factor")
+	return (*int)(&speed)
+}
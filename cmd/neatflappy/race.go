@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy"
+	"github.com/kpacha/neatflappy/champion"
+)
+
+// palette tints the champions of a race, in order
+var palette = []color.Color{
+	color.RGBA{0xff, 0x60, 0x60, 0xff},
+	color.RGBA{0x60, 0xff, 0x60, 0xff},
+	color.RGBA{0x60, 0xa0, 0xff, 0xff},
+	color.RGBA{0xff, 0xd0, 0x40, 0xff},
+	color.RGBA{0xd0, 0x60, 0xff, 0xff},
+	color.RGBA{0x40, 0xff, 0xff, 0xff},
+}
+
+// race lets a human fly the same level at the same time as one or more champions
+func race(args []string) {
+	f := flag.NewFlagSet("race", flag.ExitOnError)
+	var (
+		stores      = addStoreFlags(f)
+		cpath       = addConfigFlag(f)
+		files       = f.String("files", "", "comma-separated list of exported network files")
+		hofRanks    = f.String("hof-ranks", "", "comma-separated list of hall of fame ranks")
+		runs        = f.String("runs", "", "comma-separated list of runs whose best genome joins the race")
+		level       = f.Int("level", 1, "level to fly")
+		seed        = f.Int64("seed", 0, "seed for the random pipes of the advanced levels (0 keeps the default ones)")
+		speedFactor = addSpeedFlag(f)
+		rounds      = f.Int("rounds", 3, "number of rounds")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s race [flags]\n\nRaces against one or more champions. Press space or click to jump.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	newTranslator := func() (evo.Translator, error) { return translator(*cpath) }
+	champions, err := loadChampions(stores, newTranslator, *files, *hofRanks, *runs)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if len(champions) == 0 {
+		log.Fatal("at least one champion is required")
+	}
+
+	g := neatflappy.NewGame(*speedFactor, *rounds, len(champions)+1)
+	g.SetLevel(neatflappy.NewLevel(*level, *seed))
+	g.Scoreboard = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		for round := 1; round <= *rounds; round++ {
+			tasks := []neatflappy.Task{{
+				Jumper:  new(neatflappy.InteractiveJumper),
+				Fitness: make(chan float64),
+				Name:    "human",
+			}}
+			for i, p := range champions {
+				tasks = append(tasks, neatflappy.Task{
+					ID:      p.ID,
+					Jumper:  neatflappy.NewPhenomeJumper(p),
+					Fitness: make(chan float64),
+					Name:    fmt.Sprintf("genome-%d", p.ID),
+					Tint:    palette[i%len(palette)],
+				})
+			}
+
+			scores, err := neatflappy.Race(ctx, g.Task, tasks)
+			if err != nil {
+				return
+			}
+			for i, s := range scores {
+				log.Printf("round %d: %s scored %.2f", round, tasks[i].Name, s)
+			}
+			winner := neatflappy.Winner(scores)
+			log.Printf("round %d: %s wins!", round, tasks[winner].Name)
+		}
+		time.Sleep(2 * time.Second)
+		cancel()
+	}()
+
+	runGame(ctx, g, *speedFactor, "Flappy Gopher (human vs AI)")
+}
+
+// loadChampions returns the phenomes of the exported files, the hall of fame ranks and the
+// best genomes of the runs, all of them given as comma-separated lists. The stored genomes
+// are translated by the translator built by newTranslator, the one of the training.
+func loadChampions(stores storeFlags, newTranslator func() (evo.Translator, error), files, hofRanks, runs string) ([]evo.Phenome, error) {
+	champions := []evo.Phenome{}
+	for _, path := range splitList(files) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		net, err := champion.Load(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		champions = append(champions, evo.Phenome{ID: net.ID, Network: net})
+	}
+
+	if hofRanks == "" && runs == "" {
+		return champions, nil
+	}
+
+	t, err := newTranslator()
+	if err != nil {
+		return nil, err
+	}
+	db, err := stores.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	add := func(runID, id int64, rank int) error {
+		_, g, err := loadGenome(db, runID, id, rank)
+		if err != nil {
+			return err
+		}
+		p, err := translate(t, g)
+		if err != nil {
+			return err
+		}
+		champions = append(champions, p)
+		return nil
+	}
+
+	for _, v := range splitList(hofRanks) {
+		rank, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(noRun, 0, rank); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range splitList(runs) {
+		runID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(runID, 0, 0); err != nil {
+			return nil, err
+		}
+	}
+	return champions, nil
+}
+
+func splitList(s string) []string {
+	res := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/champion"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// simpleGenome jumps when the first input is above the bias
+func simpleGenome(id int64, fitness float64) evo.Genome {
+	in := evo.Position{Layer: 0, X: 0}
+	bias := evo.Position{Layer: 0, X: 1}
+	out := evo.Position{Layer: 1, X: 0.5}
+	sub := evo.Substrate{
+		Nodes: []evo.Node{
+			{Position: in, Neuron: evo.Input, Activation: evo.Direct},
+			{Position: bias, Neuron: evo.Input, Activation: evo.Direct},
+			{Position: out, Neuron: evo.Output, Activation: evo.SteepenedSigmoid},
+		},
+		Conns: []evo.Conn{
+			{Source: in, Target: out, Weight: 1, Enabled: true},
+			{Source: bias, Target: out, Weight: -0.5, Enabled: true},
+		},
+	}
+	return evo.Genome{ID: id, Fitness: fitness, Encoded: sub, Decoded: sub}
+}
+
+func TestLoadChampions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neatflappy-race")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// an exported network
+	net, err := champion.FromGenome(simpleGenome(5, 1))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	path := filepath.Join(dir, "champion.json")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = net.Write(file)
+	file.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// a run and a hall of fame
+	backend, db, codec := "bolt", filepath.Join(dir, "my.db"), "gob"
+	stores := storeFlags{backend: &backend, path: &db, codec: &codec}
+	s, err := stores.open()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	run, _ := s.NewRun()
+	s.PutGenome(run, simpleGenome(11, 1))
+	s.PutGenome(run, simpleGenome(12, 2))
+	s.PutHallOfFame([]store.Fame{{Run: run, Genome: simpleGenome(20, 3)}})
+	s.Close()
+
+	translated := 0
+	newTranslator := func() (evo.Translator, error) {
+		translated++
+		return champion.Translator{}, nil
+	}
+	champions, err := loadChampions(stores, newTranslator, path, "1", "1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ids := []int64{}
+	for _, p := range champions {
+		ids = append(ids, p.ID)
+		if p.Network == nil {
+			t.Errorf("genome %d without network", p.ID)
+		}
+	}
+	if !reflect.DeepEqual(ids, []int64{5, 20, 12}) || translated != 1 {
+		t.Errorf("unexpected champions %v, translator built %d times", ids, translated)
+	}
+
+	if champions, err := loadChampions(stores, newTranslator, path, "", ""); err != nil || len(champions) != 1 || translated != 1 {
+		t.Errorf("unexpected champions of the files: %+v %v", champions, err)
+	}
+	if _, err := loadChampions(stores, newTranslator, "", "2", ""); err == nil {
+		t.Error("expecting an error for a missing hall of fame rank")
+	}
+	if _, err := loadChampions(stores, newTranslator, filepath.Join(dir, "missing.json"), "", ""); err == nil {
+		t.Error("expecting an error for a missing file")
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/kpacha/neatflappy"
+)
+
+// recordCommand records the flights of a human player as a trace log, a session per life
+func recordCommand(args []string) {
+	f := flag.NewFlagSet("record", flag.ExitOnError)
+	var (
+		out         = f.String("out", "./log.txt", "path of the trace log")
+		appendLog   = f.Bool("append", false, "append the sessions to the trace log instead of truncating it")
+		sessions    = f.Int("sessions", 1, "number of lives to record, one session each")
+		level       = f.Int("level", 0, "level to fly (0 keeps the default progression)")
+		seed        = f.Int64("seed", 0, "seed for the random pipes of the advanced levels (0 keeps the default ones)")
+		speedFactor = addSpeedFlag(f)
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s record [flags]\n\nRecords the flights of the player as a trace log for the imitation training.\nEvery life is a session of its own.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	g := neatflappy.NewGame(*speedFactor, *sessions, 1)
+	// the trace log can not take back the ticks already written
+	g.RewindSeconds = 0
+	if *level > 0 {
+		g.SetLevel(neatflappy.NewLevel(*level, *seed))
+	}
+
+	traces, err := neatflappy.CreateTraceLog(*out, *appendLog, neatflappy.NewTraceHeader("human", *level, *seed))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer traces.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		defer cancel()
+		newJumper := func(w io.Writer, episode int) neatflappy.LogJumper {
+			return &neatflappy.InteractiveLogJumper{Out: w, Episode: episode}
+		}
+		if err := traces.Record(ctx, g.Task, *sessions, newJumper); err != nil {
+			log.Println("recording the sessions:", err.Error())
+			return
+		}
+		time.Sleep(2 * time.Second)
+	}()
+
+	runGame(ctx, g, *speedFactor, "Flappy Gopher (Human Edition)")
+}
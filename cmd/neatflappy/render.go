@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// render draws the topology of a genome as a graphviz graph or an SVG image
+func render(args []string) {
+	f := flag.NewFlagSet("render", flag.ExitOnError)
+	var (
+		genome = addGenomeFlags(f)
+		format = f.String("format", "svg", "output format: svg or dot")
+		out    = f.String("out", "", "write the image to this file instead of stdout")
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s render [flags]\n\nRenders the topology of a stored genome or an exported network.\nEdges are sized by weight and disabled connections are dashed.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	net, err := genome.network()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	err = writeOutput(*out, func(w io.Writer) error {
+		switch *format {
+		case "svg":
+			return net.WriteSVG(w)
+		case "dot":
+			return net.WriteDOT(w)
+		}
+		return fmt.Errorf("unknown format: %s", *format)
+	})
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
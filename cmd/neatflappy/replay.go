@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/kpacha/neatflappy"
+)
+
+// replay flies a recorded flight again in the game window
+func replay(args []string) {
+	f := flag.NewFlagSet("replay", flag.ExitOnError)
+	var (
+		replayPath  = f.String("replay", "", "replay file to watch")
+		tracePath   = f.String("trace", "", "trace log to watch, as recorded by the record command")
+		session     = f.Int("session", 0, "session of the trace log to watch (0 selects the first one)")
+		level       = f.Int("level", 0, "level to fly (0 uses the level of the replay)")
+		seed        = f.Int64("seed", 0, "seed for the random pipes of the advanced levels (0 uses the seed of the replay)")
+		speedFactor = addSpeedFlag(f)
+	)
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s replay [flags]\n\nWatches a recorded flight again, from a replay file or a session of a trace log.\n\n", os.Args[0])
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+
+	r, err := loadReplay(*replayPath, *tracePath, *session)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if *level == 0 {
+		*level = r.Level
+	}
+	if *seed == 0 {
+		*seed = r.Seed
+	}
+
+	g := neatflappy.NewGame(*speedFactor, 1, 1)
+	if *level > 0 {
+		g.SetLevel(neatflappy.NewLevel(*level, *seed))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		defer cancel()
+		task := neatflappy.Task{
+			Name:    r.Name,
+			Jumper:  r.Jumper(),
+			Fitness: make(chan float64),
+		}
+		g.Task <- task
+		log.Printf("replay %s: fitness %f", r.Name, <-task.Fitness)
+		time.Sleep(2 * time.Second)
+	}()
+
+	runGame(ctx, g, *speedFactor, fmt.Sprintf("Flappy Gopher (replay of %s)", r.Name))
+}
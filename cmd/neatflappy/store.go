@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy"
+	"github.com/kpacha/neatflappy/bolt"
+	"github.com/kpacha/neatflappy/champion"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// openStore returns the store backend of the given kind. The path is the database
+// file for bolt and the root directory for jsonl. It is ignored by the memory backend.
+// The codec only applies to the bolt backend, since jsonl always writes JSON.
+func openStore(kind, path, codec string) (store.Store, error) {
+	switch kind {
+	case "bolt":
+		c, err := store.CodecByName(codec)
+		if err != nil {
+			return nil, err
+		}
+		client, err := bolt.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		client.Codec = c
+		return client, nil
+	case "jsonl":
+		return store.NewJSONLines(path)
+	case "memory":
+		return store.NewMemory(), nil
+	}
+	return nil, fmt.Errorf("unknown store backend: %s", kind)
+}
+
+// storeFlags are the flags selecting the store
+type storeFlags struct {
+	backend *string
+	path    *string
+	codec   *string
+}
+
+func addStoreFlags(f *flag.FlagSet) storeFlags {
+	return storeFlags{
+		backend: f.String("store", "bolt", "store backend: bolt, jsonl or memory"),
+		path:    f.String("db", "my.db", "path to the bolt database or the jsonl directory"),
+		codec:   f.String("codec", "gob", "codec for the bolt records: gob or json"),
+	}
+}
+
+func (s storeFlags) open() (store.Store, error) {
+	return openStore(*s.backend, *s.path, *s.codec)
+}
+
+// noRun is the -run of the commands not selecting a run, since the run 0 holds
+// the genomes migrated from the databases written before the runs were tracked
+const noRun = -1
+
+// genomeFlags are the flags selecting a genome, from the store or from an exported file
+type genomeFlags struct {
+	storeFlags
+	file    *string
+	run     *int64
+	genome  *int64
+	hofRank *int
+}
+
+func addGenomeFlags(f *flag.FlagSet) genomeFlags {
+	return genomeFlags{
+		storeFlags: addStoreFlags(f),
+		file:       f.String("file", "", "exported network file to use instead of the store"),
+		run:        f.Int64("run", noRun, "run of the genome (0 holds the genomes stored before the runs were tracked)"),
+		genome:     f.Int64("genome", 0, "genome of the run (0 selects the best genome of the run)"),
+		hofRank:    f.Int("hof-rank", 0, "select the genome at this rank of the hall of fame instead"),
+	}
+}
+
+// selected reports if the flags select a genome
+func (g genomeFlags) selected() bool {
+	return *g.file != "" || *g.run != noRun || *g.hofRank > 0
+}
+
+// network returns the selected genome as a network, labeled with the sensor names
+func (g genomeFlags) network() (*champion.Network, error) {
+	if *g.file != "" {
+		file, err := os.Open(*g.file)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return champion.Load(file)
+	}
+
+	db, err := g.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	runID, genome, err := loadGenome(db, *g.run, *g.genome, *g.hofRank)
+	if err != nil {
+		return nil, err
+	}
+	net, err := champion.FromGenome(genome)
+	if err != nil {
+		return nil, err
+	}
+	net.Run = runID
+	net.Inputs = neatflappy.SensorNames
+	return net, nil
+}
+
+// phenome returns the selected genome ready to fly and its substrate, if known.
+// Stored genomes are translated with the translator of the experiment configured
+// at cpath, as in the training, so they fly the network that got their fitness.
+// Exported files run as champion networks.
+func (g genomeFlags) phenome(cpath string) (evo.Phenome, evo.Substrate, error) {
+	if *g.file != "" {
+		net, err := g.network()
+		if err != nil {
+			return evo.Phenome{}, evo.Substrate{}, err
+		}
+		return evo.Phenome{ID: net.ID, Network: net}, evo.Substrate{}, nil
+	}
+
+	db, err := g.open()
+	if err != nil {
+		return evo.Phenome{}, evo.Substrate{}, err
+	}
+	defer db.Close()
+
+	_, genome, err := loadGenome(db, *g.run, *g.genome, *g.hofRank)
+	if err != nil {
+		return evo.Phenome{}, evo.Substrate{}, err
+	}
+	t, err := translator(cpath)
+	if err != nil {
+		return evo.Phenome{}, evo.Substrate{}, err
+	}
+	p, err := translate(t, genome)
+	return p, genome.Decoded, err
+}
+
+// translator returns the translator of the experiment configured at cpath, the one
+// decoding the genomes during the training
+func translator(cpath string) (evo.Translator, error) {
+	exp, err := newExperiment(cpath)
+	if err != nil {
+		return nil, err
+	}
+	return exp.Translator, nil
+}
+
+// translate returns the stored genome ready to fly
+func translate(t evo.Translator, g evo.Genome) (evo.Phenome, error) {
+	net, err := t.Translate(g.Decoded)
+	if err != nil {
+		return evo.Phenome{}, err
+	}
+	return evo.Phenome{ID: g.ID, Traits: g.Traits, Network: net}, nil
+}
+
+// loadGenome returns the given rank of the hall of fame if it is positive, the
+// best genome of the run if id is zero or the genome id of the run otherwise
+func loadGenome(db store.Store, runID, id int64, rank int) (int64, evo.Genome, error) {
+	if rank > 0 {
+		entries, err := db.HallOfFame()
+		if err != nil {
+			return 0, evo.Genome{}, err
+		}
+		if rank > len(entries) {
+			return 0, evo.Genome{}, fmt.Errorf("the hall of fame has only %d entries", len(entries))
+		}
+		e := entries[rank-1]
+		return e.Run, e.Genome, nil
+	}
+	if runID == noRun {
+		return 0, evo.Genome{}, errors.New("a run, a hall of fame rank or a file is required")
+	}
+	if id == 0 {
+		g, err := db.BestOfRun(runID)
+		return runID, g, err
+	}
+	g, err := db.Genome(runID, id)
+	return runID, g, err
+}
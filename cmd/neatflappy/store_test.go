@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bbolt "github.com/boltdb/bolt"
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/bolt"
+)
+
+func TestLoadGenome_legacyRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neatflappy-cmd")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "my.db")
+
+	// a database written before the runs were tracked: the genomes under their bare ID
+	raw, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = raw.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bolt.PhenomeBucket))
+		if err != nil {
+			return err
+		}
+		for _, g := range []evo.Genome{{ID: 7, Fitness: 3}, {ID: 9, Fitness: 5}} {
+			buf := new(bytes.Buffer)
+			if err := gob.NewEncoder(buf).Encode(g); err != nil {
+				return err
+			}
+			key := []byte{0, 0, 0, 0, 0, 0, 0, byte(g.ID)}
+			if err := b.Put(key, buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	raw.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	db, err := openStore("bolt", path, "gob")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer db.Close()
+
+	run, g, err := loadGenome(db, bolt.LegacyRun, 7, 0)
+	if err != nil || run != bolt.LegacyRun || g.ID != 7 || g.Fitness != 3 {
+		t.Errorf("unexpected legacy genome: %d %+v %v", run, g, err)
+	}
+	if _, g, err := loadGenome(db, bolt.LegacyRun, 0, 0); err != nil || g.ID == 0 {
+		t.Errorf("unexpected best legacy genome: %+v %v", g, err)
+	}
+	if _, _, err := loadGenome(db, noRun, 0, 0); err == nil {
+		t.Error("expecting an error without run")
+	}
+}
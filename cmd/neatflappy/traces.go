@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/kpacha/neatflappy"
+)
+
+// traceCommands are the subcommands of traces
+var traceCommands = map[string]func(args []string){
+	"stats":   traceStats,
+	"merge":   traceMerge,
+	"dedupe":  traceDedupe,
+	"filter":  traceFilter,
+	"split":   traceSplit,
+	"convert": traceConvert,
+}
+
+// traces curates the trace logs recorded for the imitation training
+func traces(args []string) {
+	if len(args) > 0 {
+		if cmd, ok := traceCommands[args[0]]; ok {
+			cmd(args[1:])
+			return
+		}
+	}
+	names := []string{}
+	for name := range traceCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "Usage: %s traces <command> [flags] <trace logs>\n\nCommands: %v\n", os.Args[0], names)
+	os.Exit(2)
+}
+
+// traceFlags parses the flags of a traces subcommand and loads the trace logs given as arguments,
+// with their sessions and outcomes
+func traceFlags(f *flag.FlagSet, description string, args []string) ([]neatflappy.Trace, neatflappy.LogMeta) {
+	f.Usage = func() {
+		fmt.Fprintf(f.Output(), "Usage: %s traces %s [flags] <trace logs>\n\n%s\n\n", os.Args[0], f.Name(), description)
+		f.PrintDefaults()
+	}
+	f.Parse(args)
+	if f.NArg() == 0 {
+		f.Usage()
+		os.Exit(2)
+	}
+	traces, meta, err := neatflappy.LoadTraceLogs(f.Args()...)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	return traces, meta
+}
+
+// writeTraces stores the traces as a trace log at path, or stdout if the path is empty,
+// keeping the headers, the sessions and the outcomes recorded in meta
+func writeTraces(path string, traces []neatflappy.Trace, meta neatflappy.LogMeta) {
+	err := writeOutput(path, func(w io.Writer) error {
+		return neatflappy.WriteTraceLog(w, traces, meta)
+	})
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+func traceStats(args []string) {
+	f := flag.NewFlagSet("stats", flag.ExitOnError)
+	traces, _ := traceFlags(f, "Reports the samples, the jumps, the episodes and the range of every input.", args)
+
+	s := neatflappy.Stats(traces)
+	fmt.Printf("samples:    %d\n", s.Samples)
+	fmt.Printf("jumps:      %d (%.2f%%)\n", s.Jumps, 100*s.JumpRatio())
+	fmt.Printf("recordings: %d\n", s.Recordings)
+	fmt.Printf("episodes:   %d (ticks: min %d, mean %.1f, max %d)\n", s.Episodes, s.ShortestEpisode, s.MeanEpisode, s.LongestEpisode)
+	fmt.Printf("\n%-10s %10s %10s %10s\n", "input", "min", "mean", "max")
+	for i, name := range neatflappy.SensorNames {
+		if i >= len(s.Mean) {
+			break
+		}
+		fmt.Printf("%-10s %10.4f %10.4f %10.4f\n", name, s.Min[i], s.Mean[i], s.Max[i])
+	}
+}
+
+func traceMerge(args []string) {
+	f := flag.NewFlagSet("merge", flag.ExitOnError)
+	out := f.String("out", "", "write the merged log to this file instead of stdout")
+	traces, meta := traceFlags(f, "Merges the trace logs into one, renumbering the episodes.", args)
+	writeTraces(*out, traces, meta)
+}
+
+func traceDedupe(args []string) {
+	f := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	out := f.String("out", "", "write the log to this file instead of stdout")
+	traces, meta := traceFlags(f, "Drops the samples repeating the inputs and the decision of a previous one.", args)
+	deduped := neatflappy.Dedupe(traces)
+	log.Printf("%d of %d samples kept", len(deduped), len(traces))
+	writeTraces(*out, deduped, meta)
+}
+
+func traceFilter(args []string) {
+	f := flag.NewFlagSet("filter", flag.ExitOnError)
+	var (
+		out      = f.String("out", "", "write the log to this file instead of stdout")
+		minTicks = f.Int("min-ticks", 0, "drop the episodes shorter than this")
+		trim     = f.Int("trim", 0, "drop the last ticks of every episode ending with a crash, when the gopher was already doomed")
+	)
+	traces, meta := traceFlags(f, "Drops the short episodes and the samples just before the death.", args)
+	filtered := neatflappy.Filter(traces, meta.Ends, *minTicks, *trim)
+	log.Printf("%d of %d samples kept", len(filtered), len(traces))
+	writeTraces(*out, filtered, meta)
+}
+
+func traceSplit(args []string) {
+	f := flag.NewFlagSet("split", flag.ExitOnError)
+	var (
+		train      = f.String("train", "train.txt", "path of the training log")
+		validation = f.String("validation", "validation.txt", "path of the validation log")
+		mode       = f.String("mode", neatflappy.SplitEpisode, "holdout of the validation samples: random, episode or session")
+		holdout    = f.Float64("holdout", 0.2, "fraction of the samples held out for validation")
+		seed       = f.Int64("seed", 1, "seed for the holdout selection")
+	)
+	traces, meta := traceFlags(f, "Splits the samples into a training and a validation log.", args)
+
+	data, err := neatflappy.NewDataset(traces)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	t, v, err := data.Split(*mode, *holdout, *seed)
+	if err != nil {
+		log.Fatalf("splitting by %s: %s", *mode, err.Error())
+	}
+	if v == nil {
+		log.Fatal("nothing held out for validation")
+	}
+	writeTraces(*train, t.Traces, meta)
+	writeTraces(*validation, v.Traces, meta)
+	log.Printf("%d samples for training, %d for validation", t.Len(), v.Len())
+}
+
+func traceConvert(args []string) {
+	f := flag.NewFlagSet("convert", flag.ExitOnError)
+	var (
+		out    = f.String("out", "", "write the converted log to this file instead of stdout")
+		format = f.String("format", "csv", "output format: csv or jsonl")
+	)
+	traces, meta := traceFlags(f, "Converts the trace logs to another format.", args)
+
+	switch *format {
+	case "jsonl":
+		writeTraces(*out, traces, meta)
+		return
+	case "csv":
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+
+	if err := writeOutput(*out, func(w io.Writer) error { return writeCSV(w, traces) }); err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// writeCSV writes a row per sample, with the episode, the tick, the inputs and the decision
+func writeCSV(w io.Writer, traces []neatflappy.Trace) error {
+	cw := csv.NewWriter(w)
+	header := append([]string{"recording", "episode", "tick"}, neatflappy.SensorNames...)
+	if err := cw.Write(append(header, "jump")); err != nil {
+		return err
+	}
+	for _, t := range traces {
+		row := []string{strconv.Itoa(t.Recording), strconv.Itoa(t.Episode), strconv.Itoa(t.Tick)}
+		for _, v := range t.In {
+			row = append(row, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		jump := "0"
+		if t.Out {
+			jump = "1"
+		}
+		if err := cw.Write(append(row, jump)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
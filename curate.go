@@ -0,0 +1,142 @@
+package neatflappy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TraceStats summarizes a set of traces
+type TraceStats struct {
+	Samples    int
+	Jumps      int
+	Episodes   int
+	Recordings int
+	// Min, Max and Mean of every input
+	Min, Max, Mean []float64
+	// ShortestEpisode, LongestEpisode and MeanEpisode are lengths in ticks
+	ShortestEpisode int
+	LongestEpisode  int
+	MeanEpisode     float64
+}
+
+// JumpRatio returns the ratio of samples where the gopher jumped
+func (s TraceStats) JumpRatio() float64 {
+	return ratio(s.Jumps, s.Samples)
+}
+
+// Stats summarizes the traces
+func Stats(traces []Trace) TraceStats {
+	s := TraceStats{Samples: len(traces)}
+	episodes := episodeLengths(traces)
+	recordings := map[int]bool{}
+	for i, t := range traces {
+		recordings[t.Recording] = true
+		if t.Out {
+			s.Jumps++
+		}
+		if i == 0 {
+			s.Min = append([]float64{}, t.In...)
+			s.Max = append([]float64{}, t.In...)
+			s.Mean = make([]float64, len(t.In))
+		}
+		for k, v := range t.In {
+			if k >= len(s.Mean) {
+				break
+			}
+			s.Min[k] = math.Min(s.Min[k], v)
+			s.Max[k] = math.Max(s.Max[k], v)
+			s.Mean[k] += v / float64(len(traces))
+		}
+	}
+	s.Recordings = len(recordings)
+	s.Episodes = len(episodes)
+	for _, n := range episodes {
+		if s.ShortestEpisode == 0 || n < s.ShortestEpisode {
+			s.ShortestEpisode = n
+		}
+		if n > s.LongestEpisode {
+			s.LongestEpisode = n
+		}
+		s.MeanEpisode += float64(n) / float64(len(episodes))
+	}
+	return s
+}
+
+type episodeKey struct {
+	recording, episode int
+}
+
+func keyOf(t Trace) episodeKey {
+	return episodeKey{t.Recording, t.Episode}
+}
+
+// episodeLengths returns the number of traces of every episode
+func episodeLengths(traces []Trace) map[episodeKey]int {
+	lengths := map[episodeKey]int{}
+	for _, t := range traces {
+		lengths[keyOf(t)]++
+	}
+	return lengths
+}
+
+// Dedupe drops the traces repeating the inputs and the decision of a previous one of
+// the same episode. The episodes keep their ticks, but the dropped ones can not match
+// a jump in the tolerance windows of Compare.
+func Dedupe(traces []Trace) []Trace {
+	seen := map[episodeKey]map[string]bool{}
+	res := []Trace{}
+	for _, t := range traces {
+		episode := keyOf(t)
+		if seen[episode] == nil {
+			seen[episode] = map[string]bool{}
+		}
+		key := traceKey(t)
+		if seen[episode][key] {
+			continue
+		}
+		seen[episode][key] = true
+		res = append(res, t)
+	}
+	return res
+}
+
+func traceKey(t Trace) string {
+	parts := make([]string, len(t.In)+1)
+	for i, v := range t.In {
+		parts[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	parts[len(t.In)] = fmt.Sprint(t.Out)
+	return strings.Join(parts, ",")
+}
+
+// Filter drops the episodes shorter than minTicks and the last trim traces of every
+// episode ending with the death of the gopher, recorded when it was already doomed to
+// crash. The ends are the outcomes of the episodes, keyed by episode as LogMeta records
+// them; the episodes without a known outcome are taken as deaths, as the old logs only
+// recorded them.
+func Filter(traces []Trace, ends map[int]EpisodeEnd, minTicks, trim int) []Trace {
+	lengths := episodeLengths(traces)
+	seen := map[episodeKey]int{}
+	res := []Trace{}
+	for _, t := range traces {
+		key := keyOf(t)
+		n := lengths[key]
+		if n < minTicks {
+			continue
+		}
+		seen[key]++
+		if died(ends, t.Episode) && seen[key] > n-trim {
+			continue
+		}
+		res = append(res, t)
+	}
+	return res
+}
+
+// died reports if the episode ended with the death of the gopher or its outcome is unknown
+func died(ends map[int]EpisodeEnd, episode int) bool {
+	e, ok := ends[episode]
+	return !ok || e.Status == "" || e.Status == "died"
+}
@@ -0,0 +1,201 @@
+package neatflappy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	d := episodes(4, 5)
+	s := Stats(d.Traces)
+	if s.Samples != 20 || s.Jumps != 10 || s.Episodes != 4 || s.Recordings != 2 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	if s.Min[0] != 1 || s.Max[0] != 4 || s.Mean[0] != 2.5 || s.JumpRatio() != .5 {
+		t.Errorf("unexpected input stats: %+v", s)
+	}
+	if s.ShortestEpisode != 5 || s.LongestEpisode != 5 || s.MeanEpisode != 5 {
+		t.Errorf("unexpected episode stats: %+v", s)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	d := episodes(2, 5)
+	if deduped := Dedupe(d.Traces); len(deduped) != 2 || deduped[1].Episode != 2 {
+		t.Errorf("unexpected traces: %+v", deduped)
+	}
+
+	// the episodes repeating the inputs of another one keep their traces
+	traces := []Trace{}
+	for e := 1; e <= 2; e++ {
+		for tick := 0; tick < 6; tick++ {
+			in := make([]float64, len(SensorNames))
+			in[0] = float64(tick % 3)
+			traces = append(traces, Trace{In: in, Out: tick%3 == 1, Tick: tick, Episode: e})
+		}
+	}
+	deduped := Dedupe(traces)
+	if len(deduped) != 6 || deduped[3].Episode != 2 || deduped[3].Tick != 0 {
+		t.Fatalf("unexpected traces: %+v", deduped)
+	}
+	d, err := NewDataset(deduped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the jumps one tick late still match the human ones in the same episode
+	late := make([]bool, d.Len())
+	for i, tr := range d.Traces {
+		late[i] = tr.Tick == 2
+	}
+	if c := d.Compare(late, 1); c != (Confusion{TP: 2, TN: 4}) {
+		t.Errorf("unexpected confusion: %s", c)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	traces := append(episodes(3, 5).Traces, Trace{In: make([]float64, len(SensorNames)), Episode: 4})
+	ends := map[int]EpisodeEnd{
+		1: {Episode: 1, Status: "died"},
+		2: {Episode: 2, Status: "exit"},
+	}
+	filtered := Filter(traces, ends, 2, 2)
+	if len(filtered) != 11 {
+		t.Errorf("unexpected number of traces: %d", len(filtered))
+		return
+	}
+	for _, tr := range filtered {
+		if tr.Episode == 4 || (tr.Episode != 2 && tr.Tick > 2) {
+			t.Errorf("unexpected trace: %+v", tr)
+		}
+	}
+}
+
+func TestLoadTraceFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traces")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	paths := []string{}
+	for i := 0; i < 2; i++ {
+		buf := new(bytes.Buffer)
+		if err := WriteTraceLog(buf, episodes(2, 3).Traces, LogMeta{}); err != nil {
+			t.Error(err)
+			return
+		}
+		path := filepath.Join(dir, fmt.Sprintf("log%d.txt", i))
+		if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Error(err)
+			return
+		}
+		paths = append(paths, path)
+	}
+
+	traces, err := LoadTraceFiles(paths...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	s := Stats(traces)
+	if s.Samples != 12 || s.Episodes != 4 || s.Recordings != 2 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	if traces[6].Episode != 3 || traces[6].Recording == traces[0].Recording {
+		t.Errorf("unexpected trace: %+v", traces[6])
+	}
+}
+
+// writeLogs writes every log as a file of dir and returns their paths
+func writeLogs(t *testing.T, dir string, logs ...string) []string {
+	paths := []string{}
+	for i, data := range logs {
+		path := filepath.Join(dir, fmt.Sprintf("log%d.txt", i))
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestLoadTraceFiles_legacy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	traces, err := LoadTraceFiles(writeLogs(t, dir, sampleData, sampleData)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Stats(traces)
+	if s.Episodes != 2 || s.Recordings != 2 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	if last := len(traces) - 1; traces[0].Episode == traces[last].Episode {
+		t.Errorf("the logs share the episode %d", traces[0].Episode)
+	}
+}
+
+func TestWriteTraceLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	recorded := new(bytes.Buffer)
+	WriteTraceHeader(recorded, NewTraceHeader("test", 2, 7))
+	for id := 1; id <= 2; id++ {
+		WriteSession(recorded, Session{ID: id, Level: id + 1, Seed: 7})
+		d := episodes(1, 3)
+		for _, trace := range d.Traces {
+			trace.Episode = id
+			if err := json.NewEncoder(recorded).Encode(trace); err != nil {
+				t.Fatal(err)
+			}
+		}
+		WriteEpisodeEnd(recorded, EpisodeEnd{Episode: id, Ticks: 3, Fitness: float64(id)})
+	}
+
+	traces, meta, err := LoadTraceLogs(writeLogs(t, dir, sampleData, recorded.String())...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := new(bytes.Buffer)
+	if err := WriteTraceLog(merged, traces, meta); err != nil {
+		t.Fatal(err)
+	}
+	traces, meta, err = LoadTraceLogs(writeLogs(t, dir, merged.String())...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Stats(traces)
+	if s.Episodes != 3 || s.Recordings != 2 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	h, ok := meta.Headers[traces[len(traces)-1].Recording]
+	if !ok || h.Recorder != "test" || h.Level != 2 || h.Seed != 7 {
+		t.Errorf("unexpected header: %+v", h)
+	}
+	for id := 2; id <= 3; id++ {
+		if s := meta.Sessions[id]; s.ID != id || s.Level != id || s.Seed != 7 {
+			t.Errorf("unexpected session of the episode %d: %+v", id, s)
+		}
+		if e := meta.Ends[id]; e.Episode != id || e.Fitness != float64(id-1) {
+			t.Errorf("unexpected outcome of the episode %d: %+v", id, e)
+		}
+	}
+	if _, ok := meta.Ends[1]; ok {
+		t.Error("outcome for the episode recorded without it")
+	}
+}
@@ -0,0 +1,242 @@
+package neatflappy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klokare/evo"
+	"gonum.org/v1/gonum/mat"
+)
+
+// maxTraceLine is the size of the longest line accepted by LoadTraces
+const maxTraceLine = 1 << 20
+
+var ErrNoTraces = errors.New("no training data")
+
+// LoadTraces parses a trace log line by line. The inputs of the logs recorded with
+// another sensor layout are rearranged into the current one; the logs without a
+// header are accepted only if their inputs have the expected width. The errors
+// report the line of the log where they were found.
+func LoadTraces(r io.Reader) ([]Trace, error) {
+	samples, _, err := loadTraceLog(r)
+	return samples, err
+}
+
+// logMeta is the LogMeta of a single log, keyed by the recording and the episode
+// of the traces as recorded
+type logMeta struct {
+	headers  map[int]TraceHeader
+	sessions map[episodeKey]Session
+	ends     map[episodeKey]EpisodeEnd
+}
+
+func loadTraceLog(r io.Reader) ([]Trace, logMeta, error) {
+	meta := logMeta{
+		headers:  map[int]TraceHeader{},
+		sessions: map[episodeKey]Session{},
+		ends:     map[episodeKey]EpisodeEnd{},
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxTraceLine)
+	samples := []Trace{}
+	var columns []int
+	// session is the session header waiting for its first trace
+	var session *Session
+	line, recording := 0, 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		data := logRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &data); err != nil {
+			return samples, meta, fmt.Errorf("line %d: %s", line, err.Error())
+		}
+		if data.Header != nil {
+			recording++
+			var err error
+			if columns, err = data.Header.Columns(); err != nil {
+				return samples, meta, fmt.Errorf("line %d: %s", line, err.Error())
+			}
+			meta.headers[recording] = *data.Header
+			session = nil
+			continue
+		}
+		if data.Session != nil {
+			session = data.Session
+			continue
+		}
+		if data.End != nil {
+			meta.ends[episodeKey{recording, data.End.Episode}] = *data.End
+			continue
+		}
+		sample := data.Trace
+		sample.Recording = recording
+		if columns != nil {
+			sample.In = make([]float64, len(columns))
+			for i, k := range columns {
+				if k >= len(data.In) {
+					return samples, meta, fmt.Errorf("line %d: %d inputs, the header declares more", line, len(data.In))
+				}
+				sample.In[i] = data.In[k]
+			}
+		}
+		if len(sample.In) != len(SensorNames) {
+			return samples, meta, fmt.Errorf("line %d: %d inputs, expected %d (%v)", line, len(sample.In), len(SensorNames), SensorNames)
+		}
+		if session != nil {
+			meta.sessions[keyOf(sample)] = *session
+			session = nil
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return samples, meta, fmt.Errorf("line %d: %s", line+1, err.Error())
+	}
+	return samples, meta, nil
+}
+
+// LoadTraceFiles loads the traces of several logs. Every log is a recording of its own
+// and the episodes are renumbered, so the ones of different logs never share an ID.
+func LoadTraceFiles(paths ...string) ([]Trace, error) {
+	traces, _, err := LoadTraceLogs(paths...)
+	return traces, err
+}
+
+// LogMeta is what the trace logs record besides the traces: the header of every
+// recording and the session and the outcome of every episode, keyed by the
+// Recording and the Episode of the traces loaded with them
+type LogMeta struct {
+	Headers  map[int]TraceHeader
+	Sessions map[int]Session
+	Ends     map[int]EpisodeEnd
+}
+
+// LoadTraceLogs loads the traces of several logs as LoadTraceFiles does, with their
+// LogMeta. The episodes are numbered in order of appearance, the ones recorded
+// without session too, and the sessions and the outcomes follow their episodes.
+func LoadTraceLogs(paths ...string) ([]Trace, LogMeta, error) {
+	traces := []Trace{}
+	meta := LogMeta{
+		Headers:  map[int]TraceHeader{},
+		Sessions: map[int]Session{},
+		Ends:     map[int]EpisodeEnd{},
+	}
+	recordings, episodes := 0, 0
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return traces, meta, err
+		}
+		loaded, m, err := loadTraceLog(file)
+		file.Close()
+		if err != nil {
+			return traces, meta, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		if len(loaded) == 0 {
+			continue
+		}
+		ids := map[episodeKey]int{}
+		for _, t := range loaded {
+			key := keyOf(t)
+			id, ok := ids[key]
+			if !ok {
+				episodes++
+				id = episodes
+				ids[key] = id
+			}
+			t.Recording += recordings
+			t.Episode = id
+			traces = append(traces, t)
+		}
+		for recording, h := range m.headers {
+			meta.Headers[recording+recordings] = h
+		}
+		for key, id := range ids {
+			if s, ok := m.sessions[key]; ok {
+				s.ID = id
+				meta.Sessions[id] = s
+			}
+			if e, ok := m.ends[key]; ok {
+				e.Episode = id
+				meta.Ends[id] = e
+			}
+		}
+		// the traces before the first header are a recording too
+		recordings += loaded[len(loaded)-1].Recording + 1
+	}
+	return traces, meta, nil
+}
+
+// Dataset is a set of traces ready to be evaluated: the inputs of all the traces
+// are packed in a matrix, a row per trace, so every phenome can share it.
+type Dataset struct {
+	Traces []Trace
+	Inputs *mat.Dense
+
+	// ticks indexes the traces by their tick in the episode
+	ticks map[tickKey][]int
+}
+
+// tickKey identifies a tick of an episode of a recording
+type tickKey struct {
+	recording, episode, tick int
+}
+
+// NewDataset packs the traces into a dataset
+func NewDataset(traces []Trace) (*Dataset, error) {
+	if len(traces) == 0 {
+		return nil, ErrNoTraces
+	}
+	width := len(traces[0].In)
+	data := make([]float64, 0, len(traces)*width)
+	ticks := map[tickKey][]int{}
+	for i, t := range traces {
+		if len(t.In) != width {
+			return nil, fmt.Errorf("trace %d has %d inputs, expected %d", i, len(t.In), width)
+		}
+		data = append(data, t.In...)
+		key := tickKey{t.Recording, t.Episode, t.Tick}
+		ticks[key] = append(ticks[key], i)
+	}
+	return &Dataset{
+		Traces: traces,
+		Inputs: mat.NewDense(len(traces), width, data),
+		ticks:  ticks,
+	}, nil
+}
+
+// LoadDataset parses the trace log into a dataset
+func LoadDataset(r io.Reader) (*Dataset, error) {
+	traces, err := LoadTraces(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewDataset(traces)
+}
+
+// Len returns the number of traces in the dataset
+func (d *Dataset) Len() int {
+	return len(d.Traces)
+}
+
+// Predict activates the phenome with all the traces at once and returns its decisions
+func (d *Dataset) Predict(p evo.Network) ([]bool, error) {
+	outputs, err := p.Activate(d.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	if rows, _ := outputs.Dims(); rows != d.Len() {
+		return nil, fmt.Errorf("%d outputs for %d traces", rows, d.Len())
+	}
+	out := make([]bool, d.Len())
+	for k := range out {
+		out[k] = outputs.At(k, 0) > .5
+	}
+	return out, nil
+}
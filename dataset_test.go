@@ -0,0 +1,164 @@
+package neatflappy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/klokare/evo"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestLoadTraces_errors(t *testing.T) {
+	for _, tc := range []struct {
+		name, data, line string
+	}{
+		{"malformed", "{\"In\":[0,0,0,0,0,0,0]}\n{\"In\":[0,\n", "line 2:"},
+		{"width", "{\"In\":[0,0,0,0,0,0,0]}\n\n{\"In\":[0,0]}\n", "line 3:"},
+	} {
+		_, err := LoadTraces(strings.NewReader(tc.data))
+		if err == nil || !strings.HasPrefix(err.Error(), tc.line) {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+	}
+}
+
+// firstInput jumps when the first input is positive
+type firstInput struct{}
+
+func (firstInput) Activate(in evo.Matrix) (evo.Matrix, error) {
+	rows, _ := in.Dims()
+	out := mat.NewDense(rows, 1, nil)
+	for r := 0; r < rows; r++ {
+		if in.At(r, 0) > 0 {
+			out.Set(r, 0, 1)
+		}
+	}
+	return out, nil
+}
+
+func TestTrainEvaluator(t *testing.T) {
+	data := "{\"In\":[1,0,0,0,0,0,0],\"Out\":true}\n{\"In\":[0,0,0,0,0,0,0],\"Out\":false}\n{\"In\":[1,0,0,0,0,0,0],\"Out\":false}\n"
+	dataset, err := LoadDataset(bytes.NewBufferString(data))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if rows, cols := dataset.Inputs.Dims(); rows != 3 || cols != len(SensorNames) {
+		t.Errorf("unexpected dimensions: %dx%d", rows, cols)
+	}
+
+	r, err := TrainEvaluator{Data: dataset}.Evaluate(evo.Phenome{ID: 1, Network: firstInput{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Fitness != 4 || r.Solved {
+		t.Errorf("unexpected result: %+v", r)
+	}
+
+	if _, err := LoadDataset(strings.NewReader("")); err != ErrNoTraces {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTrainEvaluator_validation(t *testing.T) {
+	jumping := func(jump bool, n int) *Dataset {
+		traces := make([]Trace, n)
+		for i := range traces {
+			in := make([]float64, len(SensorNames))
+			if jump {
+				in[0] = 1
+			}
+			traces[i] = Trace{In: in, Out: true}
+		}
+		d, _ := NewDataset(traces)
+		return d
+	}
+	e := NewTrainEvaluator(jumping(true, 4), jumping(false, 2))
+	r, err := e.Evaluate(evo.Phenome{ID: 1, Network: firstInput{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Fitness != 16 || r.Solved {
+		t.Errorf("unexpected result: %+v", r)
+	}
+	if err := e.ReportValidation(evo.Population{Genomes: []evo.Genome{{ID: 1, Fitness: 16}}}); err != nil {
+		t.Error(err)
+	}
+	if len(e.scores.byID) != 0 {
+		t.Error("the scores were not reset")
+	}
+}
+
+type fixedTranslator struct {
+	net evo.Network
+}
+
+func (t fixedTranslator) Translate(evo.Substrate) (evo.Network, error) {
+	return t.net, nil
+}
+
+func TestTrainEvaluator_flights(t *testing.T) {
+	e := NewTrainEvaluator(episodes(2, 3), nil)
+	flights := 0
+	e.Flights = &Benchmark{
+		Translator: fixedTranslator{firstInput{}},
+		Levels: func() []Level {
+			flights++
+			return BenchmarkLevels()[:1]
+		},
+	}
+	e.FlightEvery = 2
+
+	if err := e.FlyBest(evo.Population{}); err != nil || flights != 0 {
+		t.Errorf("unexpected flight before any report: %d %v", flights, err)
+	}
+	for generation := 1; generation <= 2; generation++ {
+		if _, err := e.Evaluate(evo.Phenome{ID: 1, Network: firstInput{}}); err != nil {
+			t.Error(err)
+			return
+		}
+		pop := evo.Population{Generation: generation, Genomes: []evo.Genome{{ID: 1, Fitness: 1}}}
+		if err := e.ReportValidation(pop); err != nil {
+			t.Error(err)
+		}
+	}
+	if flights != 1 {
+		t.Errorf("unexpected number of flights: %d", flights)
+	}
+	if err := e.FlyBest(evo.Population{}); err != nil || flights != 2 {
+		t.Errorf("unexpected flight at the end: %d %v", flights, err)
+	}
+}
+
+func TestTrainEvaluator_flights_zeroFitness(t *testing.T) {
+	e := NewTrainEvaluator(episodes(2, 3), nil)
+	flights := 0
+	e.Flights = &Benchmark{
+		Translator: fixedTranslator{firstInput{}},
+		Levels: func() []Level {
+			flights++
+			return BenchmarkLevels()[:1]
+		},
+	}
+
+	if err := e.ReportValidation(evo.Population{Generation: 1}); err != nil {
+		t.Error(err)
+	}
+	if err := e.FlyBest(evo.Population{}); err != nil || flights != 0 {
+		t.Errorf("unexpected flight of an empty population: %d %v", flights, err)
+	}
+
+	pop := evo.Population{Generation: 2, Genomes: []evo.Genome{{ID: 3}, {ID: 4}}}
+	if err := e.ReportValidation(pop); err != nil {
+		t.Error(err)
+	}
+	if best, _, ok := e.scores.getBest(); !ok || best.ID != 3 {
+		t.Errorf("unexpected best genome without fitness: %+v %v", best, ok)
+	}
+	if err := e.FlyBest(evo.Population{}); err != nil || flights != 1 {
+		t.Errorf("unexpected flights of the best genome: %d %v", flights, err)
+	}
+}
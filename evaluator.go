@@ -1,9 +1,10 @@
 package neatflappy
 
 import (
-	"bytes"
-	"encoding/json"
+	"fmt"
+	"image/color"
 	"log"
+	"sync"
 
 	"github.com/klokare/evo"
 	"gonum.org/v1/gonum/mat"
@@ -13,6 +14,18 @@ import (
 type Evaluator struct {
 	Task       chan Task
 	Population chan evo.Population
+
+	genomes *genomeIndex
+}
+
+// NewEvaluator returns an Evaluator tagging every task with the species and the
+// substrate of its genome, as long as PreSearch gets the decoded populations
+func NewEvaluator(task chan Task, population chan evo.Population) Evaluator {
+	return Evaluator{
+		Task:       task,
+		Population: population,
+		genomes:    &genomeIndex{},
+	}
 }
 
 // Evaluate the flappy experiment with this phenome
@@ -22,6 +35,10 @@ func (e Evaluator) Evaluate(p evo.Phenome) (r evo.Result, err error) {
 		Jumper:  &evoJumper{p},
 		Fitness: make(chan float64),
 	}
+	if g, ok := e.genomes.get(p.ID); ok {
+		t.Species = g.Species
+		t.Substrate = g.Decoded
+	}
 	e.Task <- t
 
 	f := <-t.Fitness
@@ -33,12 +50,15 @@ func (e Evaluator) Evaluate(p evo.Phenome) (r evo.Result, err error) {
 	}, nil
 }
 
+// PreSearch keeps the genomes about to be evaluated
 func (e Evaluator) PreSearch(pop evo.Population) error {
-	go func() { e.Population <- pop }()
+	e.genomes.set(pop)
 	return nil
 }
 
+// PostSearch sends the evaluated population to the game
 func (e Evaluator) PostSearch(pop evo.Population) error {
+	go func() { e.Population <- pop }()
 	return nil
 }
 
@@ -46,6 +66,50 @@ type Task struct {
 	ID      int64
 	Jumper  Jumper
 	Fitness chan float64
+	// Name, if set, identifies the gopher in the scoreboard
+	Name string
+	// Tint, if set, colors the gopher. Otherwise it takes the color of its Species, if any.
+	Tint    color.Color
+	Species int64
+	// Substrate, if known, is the decoded genome driving the Jumper, drawn by the network panel
+	Substrate evo.Substrate
+	// Level, if set, replaces the level of the run the gopher joins
+	Level Level
+}
+
+// genomeIndex keeps the genomes of the population being evaluated
+type genomeIndex struct {
+	mu   sync.RWMutex
+	byID map[int64]evo.Genome
+}
+
+func (s *genomeIndex) set(pop evo.Population) {
+	if s == nil {
+		return
+	}
+	byID := make(map[int64]evo.Genome, len(pop.Genomes))
+	for _, g := range pop.Genomes {
+		byID[g.ID] = g
+	}
+	s.mu.Lock()
+	s.byID = byID
+	s.mu.Unlock()
+}
+
+// get returns the genome with the ID, if known
+func (s *genomeIndex) get(id int64) (evo.Genome, bool) {
+	if s == nil {
+		return evo.Genome{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	g, ok := s.byID[id]
+	return g, ok
+}
+
+// NewPhenomeJumper returns a Jumper driven by the phenome, as the ones flying during the training
+func NewPhenomeJumper(p evo.Phenome) Jumper {
+	return &evoJumper{p}
 }
 
 type evoJumper struct {
@@ -70,69 +134,195 @@ func (e *evoJumper) Jump(input []float64) bool {
 	return out[0] > 0.5
 }
 
+// TrainEvaluator scores the phenomes by how well they imitate the recorded traces.
+// The fitness comes from the training traces; if there are validation traces, the
+// phenomes are solved only when they imitate those too.
 type TrainEvaluator struct {
-	Log []byte
-}
+	Data       *Dataset
+	Validation *Dataset
+	// Metric is the name of the metric driving the fitness, the accuracy by default
+	Metric string
+	// Tolerance is the number of ticks a jump can be away from the recorded one and still count as right
+	Tolerance int
+	// Flights, if set, flies the best genome in the headless game every FlightEvery
+	// generations (0 means only at the end), reporting how the imitation really flies
+	Flights     *Benchmark
+	FlightEvery int
 
-// Evaluate the flappy experiment with this phenome
-func (e TrainEvaluator) Evaluate(p evo.Phenome) (r evo.Result, err error) {
-	log.Println("training phenome", p.ID)
-	samples := loadTrainingData(e.Log)
+	scores *imitationScores
+}
 
-	if len(samples) == 0 {
-		log.Fatal("no training data!")
+// NewTrainEvaluator returns a TrainEvaluator keeping the scores of every phenome,
+// so ReportValidation can summarize them
+func NewTrainEvaluator(train, validation *Dataset) TrainEvaluator {
+	return TrainEvaluator{
+		Data:       train,
+		Validation: validation,
+		scores:     &imitationScores{byID: map[int64]imitationScore{}},
 	}
+}
 
-	input := []float64{}
-	for _, sample := range samples {
-		for _, d := range sample.In {
-			input = append(input, float64(d))
-		}
+func (e TrainEvaluator) metric() (Metric, error) {
+	if e.Metric == "" {
+		return Metrics[MetricAccuracy], nil
+	}
+	m, ok := Metrics[e.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", e.Metric)
 	}
+	return m, nil
+}
 
-	in := mat.NewDense(len(samples), len(samples[0].In), input)
-	outputs, err := p.Activate(in)
+// Evaluate the phenome against the recorded traces. The fitness is the square of the
+// metric scaled by the number of traces, so the plain accuracy scores the squared
+// number of right decisions.
+func (e TrainEvaluator) Evaluate(p evo.Phenome) (r evo.Result, err error) {
+	metric, err := e.metric()
 	if err != nil {
-		log.Fatal("error training:", err.Error())
+		return evo.Result{ID: p.ID}, err
 	}
 
-	out := make([]float64, len(samples))
-	if rcv, ok := outputs.(mat.RawColViewer); ok {
-		for k := range out {
-			out[k] = rcv.RawColView(k)[0]
-		}
-	} else {
-		for k := range out {
-			out[k] = outputs.At(k, 0)
-		}
+	score := imitationScore{}
+	if score.train, err = e.Data.Confusion(p, e.Tolerance); err != nil {
+		return evo.Result{ID: p.ID}, err
 	}
+	value := metric(score.train)
+	solved := value > .9999
 
-	oks := len(samples)
-	for i, sample := range samples {
-		if sample.Out != (out[i] > .5) {
-			oks--
+	if e.Validation != nil {
+		if score.validation, err = e.Validation.Confusion(p, e.Tolerance); err != nil {
+			return evo.Result{ID: p.ID}, err
 		}
+		solved = metric(score.validation) > .9999
 	}
+	if e.scores != nil {
+		e.scores.put(p.ID, score)
+	}
+	log.Printf("phenome: %06d, %s: %.4f %s solved: %v", p.ID, e.metricName(), value, score.train, solved)
 
-	solved := oks > 9999*len(samples)/10000
-	log.Printf("phenome: %06d, oks: [%d/%d] solved: %v", p.ID, oks, len(samples), solved)
-
+	fitness := value * float64(e.Data.Len())
 	return evo.Result{
 		ID:      p.ID,
-		Fitness: float64(oks * oks),
+		Fitness: fitness * fitness,
 		Solved:  solved,
 	}, nil
 }
 
-func loadTrainingData(in []byte) []Trace {
-	decoder := json.NewDecoder(bytes.NewBuffer(in))
-	samples := []Trace{}
-	for {
-		data := Trace{}
-		if err := decoder.Decode(&data); err != nil {
-			break
+func (e TrainEvaluator) metricName() string {
+	if e.Metric == "" {
+		return MetricAccuracy
+	}
+	return e.Metric
+}
+
+// ReportValidation logs the training and validation scores of the best genome of
+// the generation and the best validation score, to spot the overfitting
+func (e TrainEvaluator) ReportValidation(pop evo.Population) error {
+	if e.scores == nil {
+		return nil
+	}
+	metric, err := e.metric()
+	if err != nil {
+		return err
+	}
+	scores := e.scores.reset()
+	if len(pop.Genomes) == 0 {
+		return nil
+	}
+	// start from a genome of the population, so one is reported even if none scored
+	best, bestValidation := pop.Genomes[0], 0.0
+	for _, g := range pop.Genomes {
+		if g.Fitness > best.Fitness {
+			best = g
+		}
+		if s, ok := scores[g.ID]; ok && metric(s.validation) > bestValidation {
+			bestValidation = metric(s.validation)
 		}
-		samples = append(samples, data)
 	}
-	return samples
+	s := scores[best.ID]
+	e.scores.setBest(best, s)
+	name := e.metricName()
+	if e.Validation == nil {
+		log.Printf("generation %d, id %d, train %s %.4f %s", pop.Generation, best.ID, name, metric(s.train), s.train)
+	} else {
+		log.Printf("generation %d, id %d, train %s %.4f %s, validation %s %.4f %s, best validation %s %.4f",
+			pop.Generation, best.ID, name, metric(s.train), s.train, name, metric(s.validation), s.validation, name, bestValidation)
+	}
+
+	if e.Flights != nil && e.FlightEvery > 0 && pop.Generation%e.FlightEvery == 0 {
+		return e.fly(best, s)
+	}
+	return nil
+}
+
+// FlyBest flies the best genome of the last generation reported by ReportValidation.
+// It is meant to be subscribed to the end of the experiment.
+func (e TrainEvaluator) FlyBest(_ evo.Population) error {
+	if e.Flights == nil || e.scores == nil {
+		return nil
+	}
+	best, s, ok := e.scores.getBest()
+	if !ok {
+		return nil
+	}
+	return e.fly(best, s)
+}
+
+// fly runs the genome over the levels of the flights and logs the outcomes next to its imitation scores
+func (e TrainEvaluator) fly(g evo.Genome, s imitationScore) error {
+	outcomes, err := e.Flights.Run(g)
+	if err != nil {
+		return err
+	}
+	metric, err := e.metric()
+	if err != nil {
+		return err
+	}
+	name := e.metricName()
+	for _, o := range outcomes {
+		log.Printf("id %d, train %s %.4f, validation %s %.4f, %s: distance %d, pipes %d, jumps %d, ticks %d, %s",
+			g.ID, name, metric(s.train), name, metric(s.validation), o.Level, o.Distance, o.Pipes, o.Jumps, o.Ticks, o.Status())
+	}
+	return nil
+}
+
+type imitationScore struct {
+	train, validation Confusion
+}
+
+// imitationScores collects the scores of the phenomes evaluated in the current
+// generation and keeps the best genome of the last reported one
+type imitationScores struct {
+	mu   sync.Mutex
+	byID map[int64]imitationScore
+
+	best      evo.Genome
+	bestScore imitationScore
+	hasBest   bool
+}
+
+func (s *imitationScores) setBest(g evo.Genome, score imitationScore) {
+	s.mu.Lock()
+	s.best, s.bestScore, s.hasBest = g, score, true
+	s.mu.Unlock()
+}
+
+func (s *imitationScores) getBest() (evo.Genome, imitationScore, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.best, s.bestScore, s.hasBest
+}
+
+func (s *imitationScores) put(id int64, score imitationScore) {
+	s.mu.Lock()
+	s.byID[id] = score
+	s.mu.Unlock()
+}
+
+func (s *imitationScores) reset() map[int64]imitationScore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scores := s.byID
+	s.byID = map[int64]imitationScore{}
+	return scores
 }
@@ -9,6 +9,8 @@ import (
 	_ "image/png"
 	"log"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/golang/freetype/truetype"
 	"github.com/klokare/evo"
@@ -49,6 +51,7 @@ const (
 	pipeIntervalX     = 8
 	pipeGapY          = 5
 	solutionThreshold = 10000
+	maxStepsPerFrame  = 256
 )
 
 var (
@@ -130,23 +133,57 @@ type Game struct {
 	mode Mode
 
 	Gopher []*Gopher
+	// Ghosts replay recorded flights. They never collide nor report any fitness.
+	Ghosts []*Gopher
 
-	// Camera
-	cameraX int
-	cameraY int
+	world
+	// fixedLevel, if set, replaces the level progression of the training
+	fixedLevel Level
+	// Seed draws the random pipes of the advanced levels of the training. It is set
+	// before the first run; 0 flies the same pipes in every run.
+	Seed int64
 
 	Task chan Task
 
 	NextPopulation chan evo.Population
 	Population     *evo.Population
 
-	level Level
-
 	iteration      int
 	maxRuns        int
 	populationSize int
 
 	speedFactor int
+	// StepsPerFrame is the number of ticks simulated on every frame, so the
+	// game can run faster than the frame rate
+	StepsPerFrame int
+	// Turbo simulates as many ticks as fit in every frame, rendering only the last one
+	Turbo bool
+	// RewindSeconds is the flight kept for rewinding the run. 0 disables the rewind.
+	RewindSeconds int
+
+	// paused stops the run, that only moves when singleStep is set
+	paused     bool
+	singleStep bool
+	// tick is the number of ticks flown by the current run
+	tick      int
+	snapshots *snapshots
+
+	// score is the best score of the ticks simulated in the current frame
+	score int
+	// ticks simulated since ticksSince, for the ticks per second display
+	ticks      int
+	ticksSince time.Time
+	tickRate   float64
+
+	// Scoreboard shows the live score of every gopher
+	Scoreboard bool
+	// ShowPopulation shows the summary of the last generation, if any
+	ShowPopulation bool
+	hud            *populationHUD
+	// selected is the gopher clicked with the right button, shown in detail
+	selected *Gopher
+	// ShowNetwork draws the network of the selected gopher, or the best one flying
+	ShowNetwork bool
 }
 
 func NewGame(speedFactor, runs, populationSize int) *Game {
@@ -155,7 +192,10 @@ func NewGame(speedFactor, runs, populationSize int) *Game {
 		Task:           make(chan Task, populationSize),
 		NextPopulation: make(chan evo.Population, 1),
 		speedFactor:    speedFactor,
-		level:          Level1(0),
+		StepsPerFrame:  1,
+		ShowPopulation: true,
+		RewindSeconds:  defaultRewindSeconds,
+		world:          world{level: Level1(0)},
 		maxRuns:        runs,
 		populationSize: populationSize,
 	}
@@ -167,33 +207,53 @@ func NewGame(speedFactor, runs, populationSize int) *Game {
 func (g *Game) init() {
 	g.cameraX = -240
 	g.cameraY = 0
+	g.tick = 0
+	if g.snapshots != nil {
+		g.snapshots.reset()
+	}
+	g.selected = nil
 
-	l := (g.iteration / g.populationSize) + 1
-	if l < level2 {
-		g.level = Level1(l)
-		// } else if l < level3 {
-		// 	g.level = Level2(l)
-		// } else if l < level4 {
-		// 	g.level = Level3(l)
-		// } else if l < level5 {
-		// 	if g.iteration%g.populationSize == 0 {
-		// 		initPipeTileYs()
-		// 	}
-		// 	g.level = Level4(l)
-		// } else if l < level6 {
-		// 	if g.iteration%g.populationSize == 0 {
-		// 		initPipeTileYs()
-		// 	}
-		// 	g.level = Level5(l)
-	} else {
-		if (g.iteration/g.populationSize)%20 == 0 {
-			initPipeTileYs()
+	for _, ghost := range g.Ghosts {
+		ghost.init()
+		if r, ok := ghost.jumper.(resetter); ok {
+			r.Reset()
 		}
-		g.level = Level6(l)
 	}
+
+	if g.fixedLevel != nil {
+		g.level = g.fixedLevel
+		return
+	}
+
+	g.level = levelOf(g.Seed, g.iteration/g.populationSize)
+}
+
+// AddGhost adds a ghost driven by the jumper, usually a Replay, starting with the next run
+func (g *Game) AddGhost(name string, jumper Jumper) {
+	ghost := NewGopher()
+	ghost.Name = name
+	ghost.jumper = jumper
+	ghost.init()
+	g.Ghosts = append(g.Ghosts, ghost)
 }
 
+// SetLevel makes every run fly the given level
+func (g *Game) SetLevel(l Level) {
+	g.fixedLevel = l
+	g.init()
+}
+
+// pressed is the jump of the player read at the start of the frame. Only the first
+// tick simulated in the frame sees it, so a press jumps once whatever the steps per frame.
+var pressed bool
+
+// jump reports if the player asks the gopher to jump in the current tick
 func jump() bool {
+	return pressed
+}
+
+// readJump reports if the player pressed any of the jump controls since the last frame
+var readJump = func() bool {
 	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
 		return true
 	}
@@ -214,6 +274,18 @@ func (g *Game) initGopher(task Task) {
 	g.Gopher[g.iteration%g.populationSize].jumper = task.Jumper
 	g.Gopher[g.iteration%g.populationSize].fitness = task.Fitness
 	g.Gopher[g.iteration%g.populationSize].Name = fmt.Sprintf("gopher-%d", g.iteration)
+	if task.Name != "" {
+		g.Gopher[g.iteration%g.populationSize].Name = task.Name
+	}
+	g.Gopher[g.iteration%g.populationSize].tint = task.Tint
+	g.Gopher[g.iteration%g.populationSize].ID = task.ID
+	g.Gopher[g.iteration%g.populationSize].species = task.Species
+	g.Gopher[g.iteration%g.populationSize].substrate = task.Substrate
+	g.Gopher[g.iteration%g.populationSize].network = nil
+	g.Gopher[g.iteration%g.populationSize].inputs = nil
+	if task.Level != nil {
+		g.level = task.Level
+	}
 }
 
 func (g *Game) ModeSetup(ctx context.Context, screen *ebiten.Image) error {
@@ -221,7 +293,7 @@ func (g *Game) ModeSetup(ctx context.Context, screen *ebiten.Image) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	case pop := <-g.NextPopulation:
-		g.Population = &pop
+		g.setPopulation(pop)
 	case task := <-g.Task:
 		g.initGopher(task)
 		g.iteration++
@@ -239,7 +311,7 @@ func (g *Game) ModeGameOver(ctx context.Context, screen *ebiten.Image) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	case pop := <-g.NextPopulation:
-		g.Population = &pop
+		g.setPopulation(pop)
 	default:
 	}
 	return nil
@@ -253,58 +325,12 @@ func (g *Game) changeModeToSetup() {
 func (g *Game) Update(ctx context.Context) func(*ebiten.Image) error {
 	return func(screen *ebiten.Image) error {
 		g.checkSpeed()
+		g.checkControls()
 
-		score := 0
-		switch g.mode {
-		case ModeSetup:
-			if err := g.ModeSetup(ctx, screen); err != nil {
-				return err
-			}
-		case ModeGameOver:
-			log.Println("game over!")
-			if err := g.ModeGameOver(ctx, screen); err != nil {
-				return err
-			}
-
-		case ModeGame:
-			totalDeads := 0
-			bestFitness := 0
-			g.cameraX += 2
-			_, successed := g.pipeAt(g.cameraX - 2)
-			successed = successed && (g.cameraX > pipeStartOffsetX) && (floorMod(g.cameraX-pipeStartOffsetX, pipeIntervalX) < 2)
-			for _, gopher := range g.Gopher {
-				if gopher.isDead {
-					totalDeads++
-					continue
-				}
-				g.update(gopher)
-				dead := g.hit(gopher)
-				// if dead {
-				// hitPlayer.Rewind()
-				// hitPlayer.Play()
-				// }
-				f := gopher.score()
-				fInt := int(f)
-				if fInt > g.level.ExitScore() || dead {
-					gopher.fitness <- f
-					gopher.isDead = true
-				}
-				if fInt > bestFitness {
-					bestFitness = fInt
-					score = fInt
-				}
-				if successed {
-					gopher.successes++
-				}
-			}
-			if totalDeads == g.populationSize {
-				if bestFitness > 100*solutionThreshold {
-					g.mode = ModeGameOver
-				} else {
-					g.changeModeToSetup()
-				}
-			}
+		if err := g.simulateFrame(ctx, screen); err != nil {
+			return err
 		}
+		score := g.score
 
 		if ebiten.IsDrawingSkipped() {
 			return nil
@@ -317,9 +343,7 @@ func (g *Game) Update(ctx context.Context) func(*ebiten.Image) error {
 			g.drawGopher(screen)
 		}
 
-		if g.Population != nil {
-			g.drawPopulation(screen)
-		}
+		g.drawPopulation(screen)
 
 		var texts []string
 		switch g.mode {
@@ -335,113 +359,193 @@ func (g *Game) Update(ctx context.Context) func(*ebiten.Image) error {
 			text.Draw(screen, l, arcadeFont, x, (i+4)*fontSize, color.White)
 		}
 
+		if g.Scoreboard && g.mode != ModeSetup {
+			g.drawScoreboard(screen)
+		}
+		g.drawSelected(screen)
+		g.drawNetwork(screen)
+
 		scoreStr := fmt.Sprintf("%04d", score)
 		text.Draw(screen, scoreStr, arcadeFont, ScreenWidth-len(scoreStr)*fontSize, fontSize, color.White)
+		steps := fmt.Sprintf("%d", g.StepsPerFrame)
+		if g.Turbo {
+			steps = "turbo"
+		}
+		if g.paused {
+			steps = "paused"
+		}
 		ebitenutil.DebugPrint(
 			screen,
 			fmt.Sprintf(
-				"Speed: %d%%. FPS: %0.2f. %s [%d]",
-				g.speedFactor, ebiten.CurrentFPS(), g.level.String(), g.populationSize),
+				"Speed: %d%%. Steps: %s. Ticks/s: %0.0f. FPS: %0.2f. %s [%d]",
+				g.speedFactor, steps, g.tickRate, ebiten.CurrentFPS(), g.level.String(), g.populationSize),
 		)
 		return nil
 	}
 }
 
-func (g *Game) checkSpeed() {
-	for k, v := range speedKeys {
-		if inpututil.IsKeyJustPressed(k) {
-			g.speedFactor = v
-			ebiten.SetMaxTPS(60 * g.speedFactor / 100)
-			return
-		}
+// step advances the simulation by a single tick, or consumes a single task while
+// setting up the next run. It reports whether there is more to do in this frame.
+func (g *Game) step(ctx context.Context, screen *ebiten.Image) (bool, error) {
+	switch g.mode {
+	case ModeSetup:
+		iteration := g.iteration
+		err := g.ModeSetup(ctx, screen)
+		return g.iteration != iteration, err
+	case ModeGameOver:
+		log.Println("game over!")
+		return false, g.ModeGameOver(ctx, screen)
 	}
-}
 
-func (g *Game) update(gopher *Gopher) {
-	shloudJump := gopher.jump(g.scan(gopher))
-	gopher.x16 += 32
-	if shloudJump {
-		gopher.jumps++
-		gopher.vy16 = -96
-		// jumpPlayer.Rewind()
-		// jumpPlayer.Play()
+	if g.paused {
+		if !g.singleStep {
+			return false, nil
+		}
+		g.singleStep = false
 	}
-	gopher.y16 += gopher.vy16 + 2
-
-	// Gravity
-	gopher.vy16 += 4
-	if gopher.vy16 > 96 {
-		gopher.vy16 = 96
+	g.saveSnapshot()
+	g.tick++
+	g.ticks++
+	totalDeads := 0
+	bestFitness := 0
+	successed := g.advance()
+	for _, ghost := range g.Ghosts {
+		g.update(ghost)
 	}
+	for _, gopher := range g.Gopher {
+		if gopher.isDead {
+			totalDeads++
+			continue
+		}
+		g.update(gopher)
+		dead := g.hit(gopher)
+		// if dead {
+		// hitPlayer.Rewind()
+		// hitPlayer.Play()
+		// }
+		f := gopher.score()
+		fInt := int(f)
+		if fInt > g.level.ExitScore() || dead {
+			if !gopher.reported {
+				gopher.fitness <- f
+				gopher.reported = true
+			}
+			gopher.isDead = true
+		}
+		if fInt > bestFitness {
+			bestFitness = fInt
+		}
+		if fInt > g.score {
+			g.score = fInt
+		}
+		if successed {
+			gopher.successes++
+		}
+	}
+	if totalDeads == g.populationSize {
+		if bestFitness > 100*solutionThreshold {
+			g.mode = ModeGameOver
+		} else {
+			g.changeModeToSetup()
+		}
+		return false, nil
+	}
+	return !g.paused, nil
 }
 
-func (g *Game) pipeAt(tileX int) (tileY int, ok bool) {
-	return g.level.PipeAt(tileX)
-}
-
-func (g *Game) scan(gopher *Gopher) []int {
-	w, h := gopherImage.Size()
-	x0 := floorDiv(gopher.x16, 16) + (w-gopherWidth)/2
-	y0 := floorDiv(gopher.y16, 16) + (h-gopherHeight)/2
-	y1 := y0 + gopherHeight
-	res := []int{8, 0, 8, 0}
-	if y0 < -tileSize*4 {
-		return res
+// checkControls handles the pause, the single step and the rewind of the run,
+// and the overlays shown
+func (g *Game) checkControls() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		g.ShowPopulation = !g.ShowPopulation
 	}
-	if y1 >= ScreenHeight-tileSize {
-		return res
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		g.ShowNetwork = !g.ShowNetwork
 	}
-	xMin := floorDiv(x0-pipeWidth, tileSize)
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		g.selected = g.gopherAt(ebiten.CursorPosition())
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.paused = !g.paused
+		g.singleStep = false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		g.paused = true
+		g.singleStep = true
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		g.rewind()
+	}
+}
 
-	for x := xMin; x < xMin+14; x++ {
-		if y, ok := g.pipeAt(x); ok {
-			res = append(res, x-xMin-7, y)
+// simulateFrame runs the ticks of the frame: StepsPerFrame of them or, in turbo mode,
+// as many as fit in the budget. The jump of the player goes to the first one.
+func (g *Game) simulateFrame(ctx context.Context, screen *ebiten.Image) error {
+	g.score = 0
+	start := time.Now()
+	pressed = readJump()
+	defer func() { pressed = false }()
+	for i := 0; g.Turbo || i < g.StepsPerFrame; i++ {
+		advanced, err := g.step(ctx, screen)
+		pressed = false
+		if err != nil {
+			return err
+		}
+		if !advanced || (g.Turbo && time.Since(start) > g.turboBudget()) {
+			break
 		}
 	}
-	if len(res) == 4 {
-		return res
-	}
-	return res[len(res)-4:]
+	g.measureTicks()
+	return nil
 }
 
-func (g *Game) hit(gopher *Gopher) bool {
-	if g.mode != ModeGame {
-		return false
+// turboBudget is the time the turbo mode can spend simulating in every frame,
+// leaving the rest of it for rendering and the input
+func (g *Game) turboBudget() time.Duration {
+	speedFactor := g.speedFactor
+	if speedFactor <= 0 {
+		speedFactor = 100
 	}
+	return time.Second * 100 / time.Duration(60*speedFactor) * 3 / 4
+}
 
-	w, h := gopherImage.Size()
-	x0 := floorDiv(gopher.x16, 16) + (w-gopherWidth)/2
-	y0 := floorDiv(gopher.y16, 16) + (h-gopherHeight)/2
-	x1 := x0 + gopherWidth
-	y1 := y0 + gopherHeight
-	if y0 < -tileSize*4 {
-		return true
+// measureTicks updates the rate of simulated ticks per second, once per second
+func (g *Game) measureTicks() {
+	now := time.Now()
+	if g.ticksSince.IsZero() {
+		g.ticksSince = now
+		return
 	}
-	if y1 >= ScreenHeight-tileSize {
-		return true
+	if elapsed := now.Sub(g.ticksSince); elapsed >= time.Second {
+		g.tickRate = float64(g.ticks) / elapsed.Seconds()
+		g.ticks = 0
+		g.ticksSince = now
 	}
-	xMin := floorDiv(x0-pipeWidth, tileSize)
-	xMax := floorDiv(x0+gopherWidth, tileSize)
+}
 
-	for x := xMin; x <= xMax; x++ {
-		y, ok := g.pipeAt(x)
-		if !ok {
-			continue
-		}
-		if x0 >= x*tileSize+pipeWidth {
-			continue
-		}
-		if x1 < x*tileSize {
-			continue
+func (g *Game) checkSpeed() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyF11):
+		g.Turbo = !g.Turbo
+		return
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		if g.StepsPerFrame < maxStepsPerFrame {
+			g.StepsPerFrame *= 2
 		}
-		if y0 < y*tileSize {
-			return true
+		return
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		if g.StepsPerFrame > 1 {
+			g.StepsPerFrame /= 2
 		}
-		if y1 >= (y+pipeGapY)*tileSize {
-			return true
+		return
+	}
+	for k, v := range speedKeys {
+		if inpututil.IsKeyJustPressed(k) {
+			g.speedFactor = v
+			ebiten.SetMaxTPS(60 * g.speedFactor / 100)
+			return
 		}
 	}
-	return false
 }
 
 func (g *Game) drawTiles(screen *ebiten.Image) {
@@ -497,37 +601,73 @@ func (g *Game) drawTiles(screen *ebiten.Image) {
 }
 
 func (g *Game) drawGopher(screen *ebiten.Image) {
+	for _, ghost := range g.Ghosts {
+		g.drawGhost(screen, ghost)
+	}
 	for _, gopher := range g.Gopher {
 		if gopher == nil || gopher.x16/16 < g.cameraX-3 {
 			continue
 		}
-		op := &ebiten.DrawImageOptions{}
-		w, h := gopherImage.Size()
-		op.GeoM.Translate(-float64(w)/2.0, -float64(h)/2.0)
-		op.GeoM.Rotate(float64(gopher.vy16) / 96.0 * math.Pi / 6)
-		op.GeoM.Translate(float64(w)/2.0, float64(h)/2.0)
-		op.GeoM.Translate(float64(gopher.x16/16.0)-float64(g.cameraX), float64(gopher.y16/16.0)-float64(g.cameraY))
+		op := g.gopherOptions(gopher)
+		cr, cg, cb, _ := gopher.color().RGBA()
+		op.ColorM.Scale(float64(cr)/0xffff, float64(cg)/0xffff, float64(cb)/0xffff, 1)
 		if gopher.isDead {
 			op.ColorM.Translate(100, 0, 0, 0)
 		}
-		op.Filter = ebiten.FilterLinear
 		screen.DrawImage(gopherImage, op)
+		switch {
+		case gopher == g.selected:
+			g.drawFrame(screen, gopher, color.White)
+		case g.isChampion(gopher):
+			g.drawFrame(screen, gopher, championColor)
+		}
+	}
+}
+
+// drawGhost renders the ghost as a translucent gopher, once it is out of the ground
+func (g *Game) drawGhost(screen *ebiten.Image, ghost *Gopher) {
+	if ghost.x16/16 < g.cameraX-3 || ghost.y16/16 > ScreenHeight {
+		return
 	}
+	op := g.gopherOptions(ghost)
+	op.ColorM.Scale(1, 1, 1, 0.35)
+	screen.DrawImage(gopherImage, op)
 }
 
-func (g *Game) drawPopulation(screen *ebiten.Image) {
-	// op := &ebiten.DrawImageOptions{}
-	// w, h := gopherImage.Size()
-	// op.GeoM.Translate(-float64(w)/2.0, -float64(h)/2.0)
-	// op.GeoM.Rotate(float64(g.Gopher.vy16) / 96.0 * math.Pi / 6)
-	// op.GeoM.Translate(float64(w)/2.0, float64(h)/2.0)
-	// op.GeoM.Translate(float64(g.Gopher.x16/16.0)-float64(g.cameraX), float64(g.Gopher.y16/16.0)-float64(g.cameraY))
-	// op.Filter = ebiten.FilterLinear
-	// screen.DrawImage(gopherImage, op)
-
-	// for _, genome := range g.Population.Genomes {
-	// 	log.Printf("genome %d: %s", genome.ID, genome.Encoded.String())
-	// }
+// gopherOptions places and rotates the gopher image relative to the camera
+func (g *Game) gopherOptions(gopher *Gopher) *ebiten.DrawImageOptions {
+	op := &ebiten.DrawImageOptions{}
+	w, h := gopherImage.Size()
+	op.GeoM.Translate(-float64(w)/2.0, -float64(h)/2.0)
+	op.GeoM.Rotate(float64(gopher.vy16) / 96.0 * math.Pi / 6)
+	op.GeoM.Translate(float64(w)/2.0, float64(h)/2.0)
+	op.GeoM.Translate(float64(gopher.x16/16.0)-float64(g.cameraX), float64(gopher.y16/16.0)-float64(g.cameraY))
+	op.Filter = ebiten.FilterLinear
+	return op
+}
+
+func (g *Game) drawScoreboard(screen *ebiten.Image) {
+	const maxLines = 10
+
+	gophers := []*Gopher{}
+	for _, gopher := range g.Gopher {
+		if gopher != nil {
+			gophers = append(gophers, gopher)
+		}
+	}
+	sort.SliceStable(gophers, func(i, j int) bool { return gophers[i].score() > gophers[j].score() })
+	if len(gophers) > maxLines {
+		gophers = gophers[:maxLines]
+	}
+
+	for i, gopher := range gophers {
+		status := ""
+		if gopher.isDead {
+			status = " X"
+		}
+		line := fmt.Sprintf("%d. %s %d%s", i+1, gopher.Name, int(gopher.score()), status)
+		text.Draw(screen, line, smallArcadeFont, 8, 2*fontSize+i*(smallFontSize+4), gopher.color())
+	}
 }
 
 var speedKeys = map[ebiten.Key]int{
@@ -0,0 +1,57 @@
+package neatflappy
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGame_simulateFrame_jump(t *testing.T) {
+	defer func(read func() bool) { readJump = read }(readJump)
+	readJump = func() bool { return false }
+
+	ctx := context.Background()
+	g := NewGame(100, 1, 1)
+	g.StepsPerFrame = 4
+	out := new(bytes.Buffer)
+	g.Task <- Task{Jumper: &InteractiveLogJumper{Out: out, Episode: 1}, Fitness: make(chan float64, 1)}
+	for g.mode == ModeSetup {
+		if _, err := g.step(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readJump = func() bool { return true }
+	if err := g.simulateFrame(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	readJump = func() bool { return false }
+	if err := g.simulateFrame(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if jumps := g.Gopher[0].jumps; jumps != 1 {
+		t.Errorf("a single press jumped %d times", jumps)
+	}
+	traces, err := LoadTraces(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jumps := 0
+	for _, trace := range traces {
+		if trace.Out {
+			jumps++
+		}
+	}
+	if len(traces) != 8 || jumps != 1 || !traces[0].Out {
+		t.Errorf("unexpected traces: %d ticks, %d jumps\n%s", len(traces), jumps, strings.TrimSpace(out.String()))
+	}
+}
+
+func TestGame_turboBudget(t *testing.T) {
+	g := NewGame(0, 1, 1)
+	if budget := g.turboBudget(); budget <= 0 {
+		t.Errorf("unexpected turbo budget without speed factor: %v", budget)
+	}
+}
@@ -2,21 +2,34 @@ package neatflappy
 
 import (
 	"encoding/json"
+	"image/color"
 	"io"
 	"log"
+
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/champion"
 )
 
 const (
+	// the size of the gopher sprite, so the simulation does not depend on the image
+	gopherImageWidth  = 60
+	gopherImageHeight = 75
+	// the size of the gopher hitbox, centered on the sprite
 	gopherWidth  = 30
 	gopherHeight = 60
 )
 
+// SensorNames are the names of the inputs built by Gopher.jump, in order
+var SensorNames = []string{"pipe1 dx", "pipe1 dy", "pipe2 dx", "pipe2 dy", "altitude", "velocity", "bias"}
+
 func NewGopher() *Gopher {
 	return &Gopher{fitness: make(chan float64)}
 }
 
 type Gopher struct {
 	Name string
+	// ID is the genome flying the gopher, if any
+	ID int64
 	// The gopher's position
 	x16  int
 	y16  int
@@ -27,18 +40,31 @@ type Gopher struct {
 
 	jumper  Jumper
 	fitness chan float64
+	tint    color.Color
+	species int64
+
+	// substrate is the decoded genome, if known, and network its cached translation
+	// for the network panel, that shows the activation for the last inputs
+	substrate evo.Substrate
+	network   *champion.Network
+	inputs    []float64
 
 	isDead bool
+	// reported is set once the fitness is sent, so a rewind never sends it twice
+	reported bool
 }
 
 func (g *Gopher) init() {
 	g.x16 = 0
 	g.y16 = 100 * 16
+	g.vy16 = 0
 	if g.jumper == nil {
 		g.jumper = new(InteractiveJumper)
 	}
 	g.isDead = false
+	g.reported = false
 	g.jumps = 0
+	g.successes = 0
 }
 
 func (g *Gopher) score() float64 {
@@ -58,6 +84,7 @@ func (g *Gopher) jump(in []int) bool {
 	input[offset+1] = (float64(g.vy16) + 96) / 192
 	input[offset+2] = 1
 
+	g.inputs = input
 	return g.jumper.Jump(input)
 }
 
@@ -71,23 +98,33 @@ func (InteractiveJumper) Jump(_ []float64) bool {
 	return jump()
 }
 
+// InteractiveLogJumper lets the player fly the gopher, logging every decision as a Trace
 type InteractiveLogJumper struct {
-	Out io.Writer
+	Out     io.Writer
+	Episode int
+
+	tick int
 }
 
-func (i InteractiveLogJumper) Jump(in []float64) bool {
+func (i *InteractiveLogJumper) Jump(in []float64) bool {
 	out := jump()
-	data := Trace{
-		In:  in,
-		Out: out,
-	}
-	if err := json.NewEncoder(i.Out).Encode(data); err != nil {
-		log.Println("error logging the game:", err.Error())
-	}
+	logTrace(i.Out, Trace{
+		In:      in,
+		Out:     out,
+		Tick:    i.tick,
+		Episode: i.Episode,
+	})
+	i.tick++
 	return out
 }
 
-type Trace struct {
-	In  []float64
-	Out bool
+// Ticks returns the number of decisions logged
+func (i *InteractiveLogJumper) Ticks() int {
+	return i.tick
+}
+
+func logTrace(w io.Writer, t Trace) {
+	if err := json.NewEncoder(w).Encode(t); err != nil {
+		log.Println("error logging the game:", err.Error())
+	}
 }
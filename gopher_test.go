@@ -1,67 +1,85 @@
 package neatflappy
 
 import (
-	"bytes"
-	"encoding/json"
-	"io"
-	"log"
+	"strings"
 	"testing"
 )
 
 func Test_DecodeTraces(t *testing.T) {
-	buf := bytes.NewBufferString(sampleData)
-	decoder := json.NewDecoder(buf)
-	i := 0
-	for {
-		data := Trace{}
-		if err := decoder.Decode(&data); err != nil && err != io.EOF {
-			t.Errorf("error decoding line %d: %s", i, err.Error())
-			break
-		} else if err == io.EOF {
-			break
+	traces, err := LoadTraces(strings.NewReader(sampleData))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(traces) != 40 {
+		t.Errorf("unexpected number of traces: %d", len(traces))
+	}
+	for i, trace := range traces {
+		if len(trace.In) != len(SensorNames) {
+			t.Errorf("trace %d: unexpected inputs %v", i, trace.In)
 		}
-		i++
-		log.Println(data)
 	}
 }
 
-var sampleData = `{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,100,0],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,100,0],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,100,0],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,100,0],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,101,1],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,102,1],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,103,1],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,105,1],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,107,2],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,109,2],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,111,2],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,113,2],"Out":false}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,284,3],"Out":false}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,288,3],"Out":false}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,292,4],"Out":false}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,296,4],"Out":true}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,290,-5],"Out":false}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,284,-5],"Out":false}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,278,-5],"Out":false}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,273,-5],"Out":false}
-{"In":[0,0,0,7,0,0,0,0,0,0,0,2,0,0,268,-4],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,193,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,199,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,205,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,211,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,217,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,223,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,229,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,235,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,241,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,247,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,253,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,259,6],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,265,6],"Out":true}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,259,-5],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,253,-5],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,247,-5],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,242,-5],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,237,-4],"Out":false}
-{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,232,-4],"Out":false}`
+func Test_DecodeTraces_width(t *testing.T) {
+	_, err := LoadTraces(strings.NewReader(legacySampleData))
+	if err == nil || !strings.Contains(err.Error(), "line 1: 16 inputs, expected 7") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestGopher_init(t *testing.T) {
+	g := NewGopher()
+	g.init()
+	g.x16, g.y16, g.vy16, g.successes, g.jumps, g.isDead = 800, 50, 40, 3, 5, true
+	g.init()
+	if g.x16 != 0 || g.y16 != 100*16 || g.vy16 != 0 || g.successes != 0 || g.jumps != 0 || g.isDead {
+		t.Errorf("the gopher keeps the previous flight: %+v", g)
+	}
+}
+
+// legacySampleData is a headerless trace log recorded with the old 16 inputs layout
+var legacySampleData = `{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,100,0],"Out":false}
+{"In":[0,0,0,0,0,0,0,0,0,0,0,0,0,0,101,1],"Out":false}`
+
+// sampleData is a headerless trace log with the inputs of SensorNames
+var sampleData = `{"In":[0,0,0,0,100,0,1],"Out":false}
+{"In":[0,0,0,0,100,0,1],"Out":false}
+{"In":[0,0,0,0,100,0,1],"Out":false}
+{"In":[0,0,0,0,100,0,1],"Out":false}
+{"In":[0,0,0,0,101,1,1],"Out":false}
+{"In":[0,0,0,0,102,1,1],"Out":false}
+{"In":[0,0,0,0,103,1,1],"Out":false}
+{"In":[0,0,0,0,105,1,1],"Out":false}
+{"In":[0,0,0,0,107,2,1],"Out":false}
+{"In":[0,0,0,0,109,2,1],"Out":false}
+{"In":[0,0,0,0,111,2,1],"Out":false}
+{"In":[0,0,0,0,113,2,1],"Out":false}
+{"In":[7,2,0,0,284,3,1],"Out":false}
+{"In":[7,2,0,0,288,3,1],"Out":false}
+{"In":[7,2,0,0,292,4,1],"Out":false}
+{"In":[7,2,0,0,296,4,1],"Out":true}
+{"In":[7,2,0,0,290,-5,1],"Out":false}
+{"In":[7,2,0,0,284,-5,1],"Out":false}
+{"In":[7,2,0,0,278,-5,1],"Out":false}
+{"In":[7,2,0,0,273,-5,1],"Out":false}
+{"In":[7,2,0,0,268,-4,1],"Out":false}
+{"In":[0,0,0,0,193,6,1],"Out":false}
+{"In":[0,0,0,0,199,6,1],"Out":false}
+{"In":[0,0,0,0,205,6,1],"Out":false}
+{"In":[0,0,0,0,211,6,1],"Out":false}
+{"In":[0,0,0,0,217,6,1],"Out":false}
+{"In":[0,0,0,0,223,6,1],"Out":false}
+{"In":[0,0,0,0,229,6,1],"Out":false}
+{"In":[0,0,0,0,235,6,1],"Out":false}
+{"In":[0,0,0,0,241,6,1],"Out":false}
+{"In":[0,0,0,0,247,6,1],"Out":false}
+{"In":[0,0,0,0,253,6,1],"Out":false}
+{"In":[0,0,0,0,259,6,1],"Out":false}
+{"In":[0,0,0,0,265,6,1],"Out":true}
+{"In":[0,0,0,0,259,-5,1],"Out":false}
+{"In":[0,0,0,0,253,-5,1],"Out":false}
+{"In":[0,0,0,0,247,-5,1],"Out":false}
+{"In":[0,0,0,0,242,-5,1],"Out":false}
+{"In":[0,0,0,0,237,-4,1],"Out":false}
+{"In":[0,0,0,0,232,-4,1],"Out":false}`
@@ -0,0 +1,60 @@
+package neatflappy
+
+import "context"
+
+// Headless flies the tasks sent by the Evaluator without rendering anything, at
+// simulation speed. Every generation flies the same level the game window would
+// show and Simulate follows the rules of the window, so a jumper gets the same
+// fitness in the headless and the windowed trainings.
+type Headless struct {
+	Task           chan Task
+	PopulationSize int
+	// Seed draws the random pipes of the advanced levels, as Game.Seed does
+	Seed int64
+
+	iteration int
+	level     Level
+}
+
+// NewHeadless returns a headless game for the population size
+func NewHeadless(populationSize int) *Headless {
+	return &Headless{
+		Task:           make(chan Task, populationSize),
+		PopulationSize: populationSize,
+	}
+}
+
+// Run flies the tasks until the context is done. The gophers of a generation fly
+// concurrently, since they never interact. Every flight is capped as the benchmark
+// ones are, so a gopher hovering forever can not stall the generation.
+func (h *Headless) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case task := <-h.Task:
+			level := h.next()
+			go func() {
+				o := Simulate(level, task.Jumper, benchmarkMaxTicks)
+				select {
+				case task.Fitness <- o.Score:
+				case <-ctx.Done():
+				}
+			}()
+		}
+	}
+}
+
+// CurrentLevel returns the level of the last generation flown
+func (h *Headless) CurrentLevel() Level {
+	return h.level
+}
+
+// next returns the level for the next task, moving to a new one at every generation
+func (h *Headless) next() Level {
+	if h.iteration%h.PopulationSize == 0 {
+		h.level = levelOf(h.Seed, h.iteration/h.PopulationSize)
+	}
+	h.iteration++
+	return h.level
+}
@@ -0,0 +1,78 @@
+package neatflappy
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHeadless(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := NewHeadless(2)
+	go h.Run(ctx)
+
+	want := Simulate(Level1(1), neverJumper{}, 0).Score
+	for generation := 0; generation < 2; generation++ {
+		tasks := []Task{}
+		for i := 0; i < 2; i++ {
+			task := Task{Jumper: neverJumper{}, Fitness: make(chan float64)}
+			h.Task <- task
+			tasks = append(tasks, task)
+		}
+		for _, task := range tasks {
+			if f := <-task.Fitness; f != want {
+				t.Errorf("generation %d: unexpected fitness %f, want %f", generation, f, want)
+			}
+		}
+		if l := h.CurrentLevel().String(); l != Level1(generation+1).String() {
+			t.Errorf("generation %d: unexpected level %s", generation, l)
+		}
+	}
+}
+
+func TestHeadless_windowed(t *testing.T) {
+	ctx := context.Background()
+	g := NewGame(100, 2, 1)
+	g.SetLevel(Level1(1))
+	want := Simulate(Level1(1), hoverJumper{}, 0).Score
+	for round := 1; round <= 2; round++ {
+		task := Task{Jumper: hoverJumper{}, Fitness: make(chan float64, 1)}
+		g.Task <- task
+		for len(task.Fitness) == 0 {
+			if _, err := g.step(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if f := <-task.Fitness; f != want {
+			t.Errorf("round %d: the window scored %f, the simulation %f", round, f, want)
+		}
+		for g.mode != ModeSetup {
+			if _, err := g.step(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestLevelOf(t *testing.T) {
+	for _, generation := range []int{level2, 25, 45} {
+		a, b := levelOf(7, generation), levelOf(7, generation)
+		other := levelOf(8, generation)
+		same := true
+		for x := 0; x < 400; x++ {
+			y1, ok1 := a.PipeAt(x)
+			y2, ok2 := b.PipeAt(x)
+			y3, _ := other.PipeAt(x)
+			_, ok := progression(generation + 1).PipeAt(x)
+			if y1 != y2 || ok1 != ok2 || ok1 != ok {
+				t.Errorf("generation %d: unexpected pipe at %d: %d %v, %d %v", generation, x, y1, ok1, y2, ok2)
+				break
+			}
+			same = same && y1 == y3
+		}
+		if same {
+			t.Errorf("generation %d: another run flies the same pipes", generation)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package neatflappy
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/store"
+)
+
+// hudMaxSpecies caps the species sizes listed by the population HUD
+const hudMaxSpecies = 8
+
+// populationHUD is the summary of the last evaluated generation shown by the game
+type populationHUD struct {
+	store.Generation
+	MedianFitness float64
+	// SpeciesSizes are the genomes of every species, largest first
+	SpeciesSizes []int
+}
+
+// newPopulationHUD summarizes the population
+func newPopulationHUD(pop evo.Population) (populationHUD, error) {
+	summary, err := store.Summarize(0, pop)
+	if err != nil {
+		return populationHUD{}, err
+	}
+
+	fitness := make([]float64, len(pop.Genomes))
+	sizes := map[int64]int{}
+	for i, g := range pop.Genomes {
+		fitness[i] = g.Fitness
+		sizes[g.Species]++
+	}
+	sort.Float64s(fitness)
+	median := fitness[len(fitness)/2]
+	if len(fitness)%2 == 0 {
+		median = (fitness[len(fitness)/2-1] + median) / 2
+	}
+
+	h := populationHUD{Generation: summary, MedianFitness: median}
+	for _, n := range sizes {
+		h.SpeciesSizes = append(h.SpeciesSizes, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(h.SpeciesSizes)))
+	return h, nil
+}
+
+// lines returns the text of the HUD
+func (h populationHUD) lines() []string {
+	sizes := []string{}
+	for i, n := range h.SpeciesSizes {
+		if i == hudMaxSpecies {
+			sizes = append(sizes, "...")
+			break
+		}
+		sizes = append(sizes, fmt.Sprintf("%d", n))
+	}
+	return []string{
+		fmt.Sprintf("Generation %d", h.Generation.Generation),
+		fmt.Sprintf("Species %d: %s", len(h.SpeciesSizes), strings.Join(sizes, " ")),
+		fmt.Sprintf("Fitness best %.2f", h.Fitness),
+		fmt.Sprintf("        mean %.2f", h.MeanFitness),
+		fmt.Sprintf("      median %.2f", h.MedianFitness),
+		fmt.Sprintf("Champion %d, complexity %d", h.Best, h.Complexity),
+	}
+}
+
+// setPopulation keeps the population received and its summary
+func (g *Game) setPopulation(pop evo.Population) {
+	g.Population = &pop
+	hud, err := newPopulationHUD(pop)
+	if err != nil {
+		g.hud = nil
+		return
+	}
+	g.hud = &hud
+}
+
+// drawPopulation renders the summary of the last generation over the ground
+func (g *Game) drawPopulation(screen *ebiten.Image) {
+	if !g.ShowPopulation || g.hud == nil {
+		return
+	}
+	const (
+		lineHeight = 16
+		width      = 240
+		margin     = 8
+	)
+	lines := g.hud.lines()
+	y := ScreenHeight - tileSize - margin - len(lines)*lineHeight
+	ebitenutil.DrawRect(screen, margin, float64(y-margin/2), width, float64(len(lines)*lineHeight+margin), color.RGBA{0, 0, 0, 0x80})
+	for i, l := range lines {
+		ebitenutil.DebugPrintAt(screen, l, 2*margin, y+i*lineHeight)
+	}
+}
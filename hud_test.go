@@ -0,0 +1,37 @@
+package neatflappy
+
+import (
+	"testing"
+
+	"github.com/klokare/evo"
+)
+
+func TestPopulationHUD(t *testing.T) {
+	pop := evo.Population{
+		Generation: 3,
+		Genomes: []evo.Genome{
+			{ID: 1, Species: 1, Fitness: 10},
+			{ID: 3, Species: 2, Fitness: 20},
+			{ID: 4, Species: 2, Fitness: 30},
+			{ID: 2, Species: 2, Fitness: 40},
+		},
+		Species: []evo.Species{{ID: 1}, {ID: 2}},
+	}
+	h, err := newPopulationHUD(pop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Best != 2 || h.Fitness != 40 || h.MeanFitness != 25 || h.MedianFitness != 25 {
+		t.Errorf("unexpected summary: %+v", h)
+	}
+	if len(h.SpeciesSizes) != 2 || h.SpeciesSizes[0] != 3 || h.SpeciesSizes[1] != 1 {
+		t.Errorf("unexpected species sizes: %v", h.SpeciesSizes)
+	}
+	if lines := h.lines(); lines[1] != "Species 2: 3 1" {
+		t.Errorf("unexpected species line %q", lines[1])
+	}
+
+	if _, err := newPopulationHUD(evo.Population{}); err == nil {
+		t.Error("empty population summarized")
+	}
+}
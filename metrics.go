@@ -0,0 +1,115 @@
+package neatflappy
+
+import (
+	"fmt"
+
+	"github.com/klokare/evo"
+)
+
+// The metrics available for scoring the imitation
+const (
+	MetricAccuracy = "accuracy"
+	MetricBalanced = "balanced"
+	MetricF1       = "f1"
+)
+
+// Metric scores the confusion matrix between 0 and 1
+type Metric func(Confusion) float64
+
+// Metrics are the metrics by name
+var Metrics = map[string]Metric{
+	MetricAccuracy: Confusion.Accuracy,
+	MetricBalanced: Confusion.BalancedAccuracy,
+	MetricF1:       Confusion.F1,
+}
+
+// Confusion is the confusion matrix of the jumps: the positives are the ticks where the human jumped
+type Confusion struct {
+	TP, FP, TN, FN int
+}
+
+// Total returns the number of decisions
+func (c Confusion) Total() int {
+	return c.TP + c.FP + c.TN + c.FN
+}
+
+// Accuracy is the ratio of right decisions. It favours the networks that never
+// jump, since the human jumps are rare.
+func (c Confusion) Accuracy() float64 {
+	return ratio(c.TP+c.TN, c.Total())
+}
+
+// BalancedAccuracy is the mean of the ratio of jumps and the ratio of glides taken as the human did
+func (c Confusion) BalancedAccuracy() float64 {
+	return (ratio(c.TP, c.TP+c.FN) + ratio(c.TN, c.TN+c.FP)) / 2
+}
+
+// F1 is the harmonic mean of the precision and the recall of the jumps
+func (c Confusion) F1() float64 {
+	return ratio(2*c.TP, 2*c.TP+c.FP+c.FN)
+}
+
+func (c Confusion) String() string {
+	return fmt.Sprintf("[tp %d fp %d tn %d fn %d]", c.TP, c.FP, c.TN, c.FN)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// Confusion activates the network with the traces and compares its decisions with
+// the recorded ones. A jump within tolerance ticks of a human jump of the same
+// episode counts as right, for both of them.
+func (d *Dataset) Confusion(p evo.Network, tolerance int) (Confusion, error) {
+	out, err := d.Predict(p)
+	if err != nil {
+		return Confusion{}, err
+	}
+	return d.Compare(out, tolerance), nil
+}
+
+// Compare builds the confusion matrix of the decisions against the recorded traces
+func (d *Dataset) Compare(out []bool, tolerance int) Confusion {
+	c := Confusion{}
+	for i, sample := range d.Traces {
+		switch {
+		case sample.Out && out[i]:
+			c.TP++
+		case sample.Out:
+			if d.near(i, tolerance, func(j int) bool { return out[j] }) {
+				c.TP++
+			} else {
+				c.FN++
+			}
+		case out[i]:
+			if d.near(i, tolerance, func(j int) bool { return d.Traces[j].Out }) {
+				c.TN++
+			} else {
+				c.FP++
+			}
+		default:
+			c.TN++
+		}
+	}
+	return c
+}
+
+// near reports if any trace of the same episode within tolerance ticks of the i-th one
+// matches. The neighbours are looked up by tick, so the order of the traces does not matter.
+func (d *Dataset) near(i, tolerance int, match func(int) bool) bool {
+	t := d.Traces[i]
+	for dt := -tolerance; dt <= tolerance; dt++ {
+		if dt == 0 {
+			continue
+		}
+		for _, j := range d.ticks[tickKey{t.Recording, t.Episode, t.Tick + dt}] {
+			if match(j) {
+				return true
+			}
+		}
+	}
+	return false
+}
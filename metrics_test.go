@@ -0,0 +1,71 @@
+package neatflappy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDataset_Compare(t *testing.T) {
+	d := episodes(1, 20)
+	for i := range d.Traces {
+		d.Traces[i].Out = i == 5 || i == 15
+	}
+	never := make([]bool, d.Len())
+
+	c := d.Compare(never, 0)
+	if c != (Confusion{TN: 18, FN: 2}) {
+		t.Errorf("unexpected confusion: %s", c)
+	}
+	if c.Accuracy() != .9 || c.BalancedAccuracy() != .5 || c.F1() != 0 {
+		t.Errorf("unexpected metrics: %f %f %f", c.Accuracy(), c.BalancedAccuracy(), c.F1())
+	}
+
+	// the first jump is late, the second one is missed and there is a jump out of place
+	late := make([]bool, d.Len())
+	late[7], late[11] = true, true
+	for _, tc := range []struct {
+		tolerance int
+		want      Confusion
+	}{
+		{0, Confusion{TN: 16, FP: 2, FN: 2}},
+		{2, Confusion{TP: 1, TN: 17, FP: 1, FN: 1}},
+	} {
+		c := d.Compare(late, tc.tolerance)
+		if c != tc.want {
+			t.Errorf("tolerance %d: unexpected confusion %s", tc.tolerance, c)
+		}
+	}
+
+	c = Confusion{TP: 1, TN: 17, FP: 1, FN: 1}
+	if math.Abs(c.BalancedAccuracy()-(0.5+17.0/18)/2) > 1e-9 || c.F1() != .5 {
+		t.Errorf("unexpected metrics: %f %f", c.BalancedAccuracy(), c.F1())
+	}
+}
+
+func TestDataset_Compare_shuffled(t *testing.T) {
+	d := episodes(2, 20)
+	late := make([]bool, d.Len())
+	for i := range d.Traces {
+		d.Traces[i].Out = d.Traces[i].Tick == 5 || d.Traces[i].Tick == 15
+		late[i] = d.Traces[i].Tick == 7 || d.Traces[i].Tick == 11
+	}
+	want := d.Compare(late, 2)
+	if want != (Confusion{TP: 2, TN: 34, FP: 2, FN: 2}) {
+		t.Fatalf("unexpected confusion: %s", want)
+	}
+
+	// the neighbouring ticks are no longer next to each other
+	order := rand.New(rand.NewSource(1)).Perm(d.Len())
+	shuffled, err := d.Subset(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]bool, len(order))
+	for k, i := range order {
+		out[k] = late[i]
+	}
+	if c := shuffled.Compare(out, 2); c != want {
+		t.Errorf("unexpected confusion of the shuffled dataset: %s, want %s", c, want)
+	}
+}
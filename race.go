@@ -0,0 +1,50 @@
+package neatflappy
+
+import (
+	"context"
+	"sync"
+)
+
+// Race flies the lanes in the same run of the game, sending all their tasks at once.
+// The game must fly as many gophers per run as lanes. It returns the fitness of every
+// lane, in order, once all of them are over.
+func Race(ctx context.Context, tasks chan<- Task, lanes []Task) ([]float64, error) {
+	scores := make([]float64, len(lanes))
+	errs := make([]error, len(lanes))
+	wg := new(sync.WaitGroup)
+	for i, lane := range lanes {
+		wg.Add(1)
+		go func(i int, lane Task) {
+			defer wg.Done()
+			select {
+			case tasks <- lane:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			select {
+			case scores[i] = <-lane.Fitness:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+			}
+		}(i, lane)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return scores, err
+		}
+	}
+	return scores, nil
+}
+
+// Winner returns the lane with the best score, the first one on ties
+func Winner(scores []float64) int {
+	winner := 0
+	for i, s := range scores {
+		if s > scores[winner] {
+			winner = i
+		}
+	}
+	return winner
+}
@@ -0,0 +1,84 @@
+package neatflappy
+
+import (
+	"context"
+	"testing"
+)
+
+// everyJumper jumps every n ticks
+type everyJumper struct {
+	n, tick int
+}
+
+func (e *everyJumper) Jump([]float64) bool {
+	e.tick++
+	return e.tick%e.n == 0
+}
+
+func TestRace(t *testing.T) {
+	level := Level1(1)
+	jumpers := func() []Jumper {
+		return []Jumper{neverJumper{}, &everyJumper{n: 6}, &everyJumper{n: 9}}
+	}
+	want := []Outcome{}
+	ticks := map[int]bool{}
+	for _, j := range jumpers() {
+		o := Simulate(level, j, 0)
+		want = append(want, o)
+		ticks[o.Ticks] = true
+	}
+	if len(ticks) < 3 {
+		t.Fatalf("the lanes should die at different ticks: %+v", want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// the game flies the lanes as they arrive, so the shorter flights end first
+	tasks := make(chan Task)
+	go func() {
+		for {
+			select {
+			case task := <-tasks:
+				go func() { task.Fitness <- Simulate(level, task.Jumper, 0).Score }()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	lanes := []Task{}
+	for i, j := range jumpers() {
+		lanes = append(lanes, Task{ID: int64(i + 1), Jumper: j, Fitness: make(chan float64)})
+	}
+	scores, err := Race(ctx, tasks, lanes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, o := range want {
+		if scores[i] != o.Score {
+			t.Errorf("lane %d: scored %f, want %f", i, scores[i], o.Score)
+		}
+	}
+	best := 0
+	for i, o := range want {
+		if o.Score > want[best].Score {
+			best = i
+		}
+	}
+	if w := Winner(scores); w != best {
+		t.Errorf("unexpected winner %d, want %d", w, best)
+	}
+}
+
+func TestRace_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Race(ctx, make(chan Task), []Task{{Fitness: make(chan float64)}}); err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWinner(t *testing.T) {
+	if w := Winner([]float64{3, 5, 5, 1}); w != 1 {
+		t.Errorf("unexpected winner %d", w)
+	}
+}
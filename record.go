@@ -0,0 +1,166 @@
+package neatflappy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// LogJumper is a Jumper logging its traces
+type LogJumper interface {
+	Jumper
+	// Ticks returns the number of decisions logged
+	Ticks() int
+}
+
+// CorrectionJumper lets a Jumper fly the gopher while the player can force a jump
+// at any moment. Every visited state is logged with the decision of the player,
+// so the traces teach what to do in the states the agent drives the gopher into.
+type CorrectionJumper struct {
+	Agent   Jumper
+	Out     io.Writer
+	Episode int
+
+	tick int
+}
+
+func (c *CorrectionJumper) Jump(in []float64) bool {
+	agent := c.Agent.Jump(in)
+	human := jump()
+	logTrace(c.Out, Trace{In: in, Out: human, Tick: c.tick, Episode: c.Episode})
+	c.tick++
+	return human || agent
+}
+
+// Ticks returns the number of decisions logged
+func (c *CorrectionJumper) Ticks() int {
+	return c.tick
+}
+
+// TraceLog is a trace log open for recording new sessions
+type TraceLog struct {
+	Header TraceHeader
+
+	file *os.File
+	next int
+}
+
+// CreateTraceLog opens the trace log at path and writes the header. If appendLog is
+// set, the sessions are added after the existing ones, as long as they were recorded
+// by a compatible version of the game; otherwise the log is truncated.
+func CreateTraceLog(path string, appendLog bool, header TraceHeader) (*TraceLog, error) {
+	l := &TraceLog{Header: header, next: 1}
+	mode := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendLog {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		if err := l.scan(path); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, mode, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// every recording sitting starts with its own header
+	if err := WriteTraceHeader(file, header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	l.file = file
+	return l, nil
+}
+
+// scan checks the header of the existing log and keeps numbering its sessions
+func (l *TraceLog) scan(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	previous, ok, err := ReadTraceHeader(file)
+	if err != nil {
+		return err
+	}
+	if ok && !previous.Compatible(l.Header) {
+		return fmt.Errorf("%s was recorded with another version of the game: %+v", path, previous)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	sessions, err := LogSessions(file)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s.ID >= l.next {
+			l.next = s.ID + 1
+		}
+	}
+	return nil
+}
+
+// Close closes the trace log
+func (l *TraceLog) Close() error {
+	return l.file.Close()
+}
+
+// Record sends the game a task per session, flown by the jumpers built by newJumper,
+// and logs the start and the end of every session around their traces. Without a
+// fixed level in the header, the sessions follow the levels of a training run, one per
+// session, seeded by the header or by a seed drawn for the recording. Every task pins
+// the level of its session, so the log records the level the game actually flies.
+func (l *TraceLog) Record(ctx context.Context, tasks chan<- Task, sessions int, newJumper func(w io.Writer, episode int) LogJumper) error {
+	runSeed := l.Header.Seed
+	if runSeed == 0 {
+		runSeed = NewRunSeed()
+	}
+	for i := 0; i < sessions; i++ {
+		session := Session{
+			ID:      l.next,
+			Level:   l.Header.Level,
+			Seed:    l.Header.Seed,
+			Started: time.Now(),
+		}
+		if session.Level == 0 {
+			session.Level, session.Seed = progressionLevel(runSeed, i)
+		}
+		l.next++
+		if err := WriteSession(l.file, session); err != nil {
+			return err
+		}
+		jumper := newJumper(l.file, session.ID)
+		task := Task{
+			Jumper:  jumper,
+			Fitness: make(chan float64),
+			Level:   NewLevel(session.Level, session.Seed),
+		}
+		select {
+		case tasks <- task:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		select {
+		case f := <-task.Fitness:
+			log.Printf("session %d: fitness %f", session.ID, f)
+			end := EpisodeEnd{Episode: session.ID, Ticks: jumper.Ticks(), Fitness: f, Status: "died"}
+			// the game only reports the fitness when the gopher dies or exits the level
+			if int(f) > task.Level.ExitScore() {
+				end.Status = "exit"
+			}
+			if err := WriteEpisodeEnd(l.file, end); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
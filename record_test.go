@@ -0,0 +1,300 @@
+package neatflappy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// scriptedJumper logs a trace per decision, jumping every other tick
+type scriptedJumper struct {
+	out     io.Writer
+	episode int
+	tick    int
+}
+
+func (s *scriptedJumper) Jump(in []float64) bool {
+	jump := s.tick%2 == 1
+	logTrace(s.out, Trace{In: in, Out: jump, Tick: s.tick, Episode: s.episode})
+	s.tick++
+	return jump
+}
+
+func (s *scriptedJumper) Ticks() int {
+	return s.tick
+}
+
+// fakeGame flies every task for 3 ticks
+func fakeGame() chan Task {
+	tasks := make(chan Task)
+	go func() {
+		for task := range tasks {
+			for i := 0; i < 3; i++ {
+				task.Jumper.Jump(make([]float64, len(SensorNames)))
+			}
+			task.Fitness <- 1
+		}
+	}()
+	return tasks
+}
+
+func TestTraceLog_Record(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.txt")
+
+	tasks := fakeGame()
+	defer close(tasks)
+
+	newJumper := func(w io.Writer, episode int) LogJumper {
+		return &scriptedJumper{out: w, episode: episode}
+	}
+	for _, appendLog := range []bool{false, true} {
+		l, err := CreateTraceLog(path, appendLog, NewTraceHeader("test", 1, 2))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err := l.Record(context.Background(), tasks, 2, newJumper); err != nil {
+			t.Error(err)
+		}
+		l.Close()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	sessions, err := LogSessions(file)
+	file.Close()
+	if err != nil || len(sessions) != 4 || sessions[3].ID != 4 || sessions[0].Seed != 2 {
+		t.Errorf("unexpected sessions: %+v %v", sessions, err)
+	}
+
+	traces, err := LoadTraceFiles(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	s := Stats(traces)
+	if s.Samples != 12 || s.Jumps != 4 || s.Episodes != 4 || s.Recordings != 2 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+
+	header := NewTraceHeader("test", 1, 2)
+	header.Physics.Gravity++
+	if _, err := CreateTraceLog(path, true, header); err == nil {
+		t.Error("expecting an error appending to an incompatible log")
+	}
+}
+
+func TestTraceLog_Record_progression(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.txt")
+
+	newJumper := func(w io.Writer, episode int) LogJumper {
+		return &scriptedJumper{out: w, episode: episode}
+	}
+	for _, seed := range []int64{0, 2} {
+		// the game flies the level pinned by every task
+		tasks := make(chan Task)
+		levels := []Level{}
+		go func() {
+			for task := range tasks {
+				levels = append(levels, task.Level)
+				task.Jumper.Jump(make([]float64, len(SensorNames)))
+				task.Fitness <- 1
+			}
+		}()
+
+		l, err := CreateTraceLog(path, false, NewTraceHeader("test", 0, seed))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = l.Record(context.Background(), tasks, 45, newJumper)
+		close(tasks)
+		l.Close()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		sessions, err := LogSessions(file)
+		file.Close()
+		if err != nil || len(sessions) != 45 || len(levels) != 45 {
+			t.Errorf("unexpected sessions: %+v %v", sessions, err)
+			return
+		}
+		for i, s := range sessions {
+			if s.Level != i+1 {
+				t.Errorf("session %d: unexpected level %d", s.ID, s.Level)
+				continue
+			}
+			if seed == 0 {
+				// the recording draws a seed of its own, new every 20 sessions
+				if s.Seed == 0 || (i%20 != 0 && s.Seed != sessions[i-1].Seed) || (i%20 == 0 && i > 0 && s.Seed == sessions[i-1].Seed) {
+					t.Errorf("session %d: unexpected seed %d", s.ID, s.Seed)
+				}
+			} else if _, expected := progressionLevel(seed, i); s.Seed != expected {
+				t.Errorf("session %d: unexpected seed %d, want %d", s.ID, s.Seed, expected)
+				continue
+			}
+			expected := NewLevel(progressionLevel(seed, i))
+			if seed == 0 {
+				expected = NewLevel(s.Level, s.Seed)
+			}
+			// replaying the session builds the level the game flew
+			recorded := NewLevel(s.Level, s.Seed)
+			for x := 0; x < 400; x++ {
+				y1, ok1 := recorded.PipeAt(x)
+				y2, ok2 := levels[i].PipeAt(x)
+				y3, ok3 := expected.PipeAt(x)
+				if y1 != y2 || ok1 != ok2 || y1 != y3 || ok1 != ok3 {
+					t.Errorf("session %d: unexpected pipe at %d: %d %v, flown %d %v, expected %d %v", s.ID, x, y1, ok1, y2, ok2, y3, ok3)
+					break
+				}
+			}
+		}
+	}
+}
+
+// recordSessions records the sessions of the scriptedJumper flying the level 1 in the game
+func recordSessions(path string, sessions int) error {
+	ctx := context.Background()
+	g := NewGame(100, sessions, 1)
+	g.RewindSeconds = 0
+	g.SetLevel(Level1(1))
+	l, err := CreateTraceLog(path, false, NewTraceHeader("test", 1, 0))
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	done := make(chan error)
+	go func() {
+		done <- l.Record(ctx, g.Task, sessions, func(w io.Writer, episode int) LogJumper {
+			return &scriptedJumper{out: w, episode: episode}
+		})
+	}()
+	for {
+		select {
+		case err := <-done:
+			return err
+		default:
+			if _, err := g.step(ctx, nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func TestTraceLog_Record_game(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.txt")
+	if err := recordSessions(path, 2); err != nil {
+		t.Error(err)
+		return
+	}
+
+	traces, err := LoadTraceFiles(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	sessions := map[int][]Trace{}
+	for _, trace := range traces {
+		sessions[trace.Episode] = append(sessions[trace.Episode], trace)
+	}
+	first, second := sessions[1], sessions[2]
+	if len(first) == 0 || len(first) != len(second) {
+		t.Fatalf("unexpected sessions: %d and %d ticks", len(first), len(second))
+	}
+	for i := range first {
+		if !reflect.DeepEqual(first[i].In, second[i].In) {
+			t.Errorf("tick %d: the second session flies %v, the first one %v", i, second[i].In, first[i].In)
+			break
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer file.Close()
+	ends := []EpisodeEnd{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := logRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil && record.End != nil {
+			ends = append(ends, *record.End)
+		}
+	}
+	if len(ends) != 2 || ends[0].Fitness != ends[1].Fitness {
+		t.Errorf("unexpected outcomes: %+v", ends)
+	}
+}
+
+func TestCorrectionJumper(t *testing.T) {
+	defer func(p bool) { pressed = p }(pressed)
+
+	out := new(bytes.Buffer)
+	c := &CorrectionJumper{Agent: &scriptedJumper{out: ioutil.Discard}, Out: out, Episode: 3}
+	in := make([]float64, len(SensorNames))
+	// the agent jumps every other tick, starting from the second one
+	for i, tc := range []struct {
+		pressed bool
+		jump    bool
+	}{
+		{pressed: true, jump: true},
+		{pressed: false, jump: true},
+		{pressed: false, jump: false},
+		{pressed: false, jump: true},
+		{pressed: true, jump: true},
+	} {
+		pressed = tc.pressed
+		if jump := c.Jump(in); jump != tc.jump {
+			t.Errorf("tick %d: unexpected decision %v", i, jump)
+		}
+	}
+
+	traces, err := LoadTraces(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 5 || c.Ticks() != 5 {
+		t.Fatalf("unexpected traces: %d for %d ticks", len(traces), c.Ticks())
+	}
+	for i, trace := range traces {
+		if human := i == 0 || i == 4; trace.Out != human || trace.Tick != i || trace.Episode != 3 {
+			t.Errorf("the trace %d does not record the player: %+v", i, trace)
+		}
+	}
+}
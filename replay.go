@@ -0,0 +1,149 @@
+package neatflappy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Replay is a recorded flight: the ticks where the gopher jumped. Since the
+// physics are deterministic, the jumps are enough to fly the same path again.
+type Replay struct {
+	Name  string
+	Level int
+	Seed  int64
+	Ticks int
+	Jumps []int
+}
+
+// LoadReplay reads a replay file
+func LoadReplay(r io.Reader) (Replay, error) {
+	replay := Replay{}
+	err := json.NewDecoder(r).Decode(&replay)
+	return replay, err
+}
+
+// Write stores the replay as a JSON document
+func (r Replay) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// ReplayFromLog builds a replay from a session of a trace log, as written by
+// InteractiveLogJumper. Every trace is a tick of the flight. Session 0 selects the
+// first session of the log.
+func ReplayFromLog(r io.Reader, name string, session int) (Replay, error) {
+	replay := Replay{Name: name}
+	decoder := json.NewDecoder(r)
+	// the traces recorded before any session header belong to the first session
+	inside, started := session == 0, false
+	for {
+		data := logRecord{}
+		err := decoder.Decode(&data)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return replay, err
+		}
+		if data.Session != nil {
+			if started {
+				// the selected session is over
+				return replay, nil
+			}
+			inside = session == 0 || data.Session.ID == session
+			if inside {
+				started = true
+				replay.Level = data.Session.Level
+				replay.Seed = data.Session.Seed
+			}
+			continue
+		}
+		if !inside || !data.isTrace() {
+			continue
+		}
+		started = true
+		if data.Out {
+			replay.Jumps = append(replay.Jumps, replay.Ticks)
+		}
+		replay.Ticks++
+	}
+	if !started && session != 0 {
+		return replay, fmt.Errorf("session %d not found", session)
+	}
+	return replay, nil
+}
+
+// Jumper returns a Jumper repeating the recorded jumps
+func (r Replay) Jumper() Jumper {
+	jumps := make([]int, len(r.Jumps))
+	copy(jumps, r.Jumps)
+	sort.Ints(jumps)
+	return &replayJumper{jumps: jumps}
+}
+
+type replayJumper struct {
+	jumps []int
+	next  int
+	tick  int
+}
+
+func (r *replayJumper) Jump(_ []float64) bool {
+	tick := r.tick
+	r.tick++
+	for r.next < len(r.jumps) && r.jumps[r.next] < tick {
+		r.next++
+	}
+	if r.next < len(r.jumps) && r.jumps[r.next] == tick {
+		r.next++
+		return true
+	}
+	return false
+}
+
+// Reset rewinds the replay to its first tick
+func (r *replayJumper) Reset() {
+	r.next = 0
+	r.tick = 0
+}
+
+// Seek moves the replay to the tick
+func (r *replayJumper) Seek(tick int) {
+	r.tick = tick
+	r.next = sort.SearchInts(r.jumps, tick)
+}
+
+// Recorder wraps a Jumper, recording its decisions into a Replay
+type Recorder struct {
+	Jumper Jumper
+	Replay Replay
+}
+
+func (r *Recorder) Jump(in []float64) bool {
+	out := r.Jumper.Jump(in)
+	if out {
+		r.Replay.Jumps = append(r.Replay.Jumps, r.Replay.Ticks)
+	}
+	r.Replay.Ticks++
+	return out
+}
+
+// Reset clears the recorded flight
+func (r *Recorder) Reset() {
+	r.Replay.Jumps = nil
+	r.Replay.Ticks = 0
+}
+
+// Seek forgets the flight recorded from the tick on
+func (r *Recorder) Seek(tick int) {
+	if tick >= r.Replay.Ticks {
+		return
+	}
+	r.Replay.Jumps = r.Replay.Jumps[:sort.SearchInts(r.Replay.Jumps, tick)]
+	r.Replay.Ticks = tick
+}
+
+// resetter is implemented by the stateful jumpers that can restart their flight
+type resetter interface {
+	Reset()
+}
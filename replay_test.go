@@ -0,0 +1,177 @@
+package neatflappy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReplayFromLog(t *testing.T) {
+	replay, err := ReplayFromLog(bytes.NewBufferString(sampleData), "sample", 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if replay.Name != "sample" || replay.Ticks != 40 || !reflect.DeepEqual(replay.Jumps, []int{15, 33}) {
+		t.Errorf("unexpected replay: %+v", replay)
+	}
+}
+
+func TestReplay_Jumper(t *testing.T) {
+	replay := Replay{Ticks: 6, Jumps: []int{4, 1}}
+	recorder := &Recorder{Jumper: replay.Jumper()}
+
+	for round := 0; round < 2; round++ {
+		recorder.Reset()
+		if r, ok := recorder.Jumper.(resetter); ok {
+			r.Reset()
+		}
+		jumps := []bool{}
+		for i := 0; i < replay.Ticks; i++ {
+			jumps = append(jumps, recorder.Jump(nil))
+		}
+		if !reflect.DeepEqual(jumps, []bool{false, true, false, false, true, false}) {
+			t.Errorf("round %d: unexpected jumps %v", round, jumps)
+		}
+		if !reflect.DeepEqual(recorder.Replay.Jumps, []int{1, 4}) || recorder.Replay.Ticks != 6 {
+			t.Errorf("round %d: unexpected recording %+v", round, recorder.Replay)
+		}
+	}
+}
+
+func TestReplayFromLog_sessions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	for id := 1; id <= 2; id++ {
+		if err := WriteSession(buf, Session{ID: id, Level: id, Seed: int64(10 * id)}); err != nil {
+			t.Error(err)
+			return
+		}
+		for tick := 0; tick < 3*id; tick++ {
+			if err := json.NewEncoder(buf).Encode(Trace{In: make([]float64, len(SensorNames)), Out: tick == id, Tick: tick, Episode: id}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}
+	data := buf.String()
+
+	sessions, err := LogSessions(bytes.NewBufferString(data))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(sessions) != 2 || sessions[0].ID != 1 || sessions[1].Seed != 20 {
+		t.Errorf("unexpected sessions: %+v", sessions)
+	}
+
+	for _, tc := range []struct {
+		session int
+		want    Replay
+	}{
+		{0, Replay{Name: "log", Level: 1, Seed: 10, Ticks: 3, Jumps: []int{1}}},
+		{1, Replay{Name: "log", Level: 1, Seed: 10, Ticks: 3, Jumps: []int{1}}},
+		{2, Replay{Name: "log", Level: 2, Seed: 20, Ticks: 6, Jumps: []int{2}}},
+	} {
+		replay, err := ReplayFromLog(bytes.NewBufferString(data), "log", tc.session)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !reflect.DeepEqual(replay, tc.want) {
+			t.Errorf("session %d: unexpected replay %+v", tc.session, replay)
+		}
+	}
+
+	if _, err := ReplayFromLog(bytes.NewBufferString(data), "log", 3); err == nil {
+		t.Error("expecting an error for an unknown session")
+	}
+	if samples, err := LoadTraces(bytes.NewBufferString(data)); err != nil || len(samples) != 9 {
+		t.Errorf("unexpected samples: %d %v", len(samples), err)
+	}
+}
+
+// inputsJumper keeps the inputs of every decision of the jumper
+type inputsJumper struct {
+	Jumper
+	in [][]float64
+}
+
+func (i *inputsJumper) Jump(in []float64) bool {
+	i.in = append(i.in, append([]float64{}, in...))
+	return i.Jumper.Jump(in)
+}
+
+func (i *inputsJumper) Reset() {
+	i.in = nil
+	if r, ok := i.Jumper.(resetter); ok {
+		r.Reset()
+	}
+}
+
+func TestReplayFromLog_game(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.txt")
+	if err := recordSessions(path, 2); err != nil {
+		t.Error(err)
+		return
+	}
+	traces, err := LoadTraceFiles(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	recorded := [][]float64{}
+	for _, trace := range traces {
+		if trace.Episode == 2 {
+			recorded = append(recorded, trace.In)
+		}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	replay, err := ReplayFromLog(file, "log", 2)
+	file.Close()
+	if err != nil || replay.Ticks != len(recorded) {
+		t.Fatalf("unexpected replay of %d ticks: %+v %v", len(recorded), replay, err)
+	}
+
+	ctx := context.Background()
+	g := NewGame(100, 2, 1)
+	g.SetLevel(Level1(1))
+	ghost := &inputsJumper{Jumper: replay.Jumper()}
+	g.AddGhost("ghost", ghost)
+	for round := 1; round <= 2; round++ {
+		player := &inputsJumper{Jumper: replay.Jumper()}
+		task := Task{Jumper: player, Fitness: make(chan float64, 1)}
+		g.Task <- task
+		for len(task.Fitness) == 0 {
+			if _, err := g.step(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !reflect.DeepEqual(player.in, recorded) {
+			t.Errorf("round %d: the replay drifts from the recording", round)
+		}
+		if len(ghost.in) < len(recorded) || !reflect.DeepEqual(ghost.in[:len(recorded)], recorded) {
+			t.Errorf("round %d: the ghost drifts from the recording", round)
+		}
+		// finish the round, so the next one starts
+		for g.mode != ModeSetup {
+			if _, err := g.step(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
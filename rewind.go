@@ -0,0 +1,134 @@
+package neatflappy
+
+// defaultRewindSeconds is the flight kept by the games for rewinding
+const defaultRewindSeconds = 5
+
+// gopherState is the part of a gopher changing while it flies
+type gopherState struct {
+	x16, y16, vy16   int
+	successes, jumps int
+	isDead           bool
+}
+
+func (s *gopherState) save(g *Gopher) {
+	*s = gopherState{x16: g.x16, y16: g.y16, vy16: g.vy16, successes: g.successes, jumps: g.jumps, isDead: g.isDead}
+}
+
+func (s gopherState) restore(g *Gopher) {
+	g.x16, g.y16, g.vy16 = s.x16, s.y16, s.vy16
+	g.successes, g.jumps = s.successes, s.jumps
+	g.isDead = s.isDead
+}
+
+// snapshot is the state of the run before a tick
+type snapshot struct {
+	tick    int
+	cameraX int
+	cameraY int
+	gophers []gopherState
+	ghosts  []gopherState
+}
+
+// snapshots is a ring buffer keeping the last ticks of the run. The slots are
+// reused, so taking a snapshot every tick does not allocate once the ring is full.
+type snapshots struct {
+	ring []snapshot
+	next int
+	size int
+}
+
+func newSnapshots(capacity int) *snapshots {
+	return &snapshots{ring: make([]snapshot, capacity)}
+}
+
+// push returns the slot for the next snapshot, overwriting the oldest one if full
+func (s *snapshots) push() *snapshot {
+	slot := &s.ring[s.next]
+	s.next = (s.next + 1) % len(s.ring)
+	if s.size < len(s.ring) {
+		s.size++
+	}
+	return slot
+}
+
+// back drops the last n snapshots, or as many as kept, and returns the oldest
+// one dropped. It returns nil if there is none.
+func (s *snapshots) back(n int) *snapshot {
+	if n > s.size {
+		n = s.size
+	}
+	if n == 0 {
+		return nil
+	}
+	s.next = floorMod(s.next-n, len(s.ring))
+	s.size -= n
+	return &s.ring[s.next]
+}
+
+// reset drops every snapshot
+func (s *snapshots) reset() {
+	s.next = 0
+	s.size = 0
+}
+
+// seeker is implemented by the stateful jumpers that can move their flight to a tick
+type seeker interface {
+	Seek(tick int)
+}
+
+// saveSnapshot keeps the state of the run before the next tick
+func (g *Game) saveSnapshot() {
+	if g.RewindSeconds <= 0 {
+		return
+	}
+	if g.snapshots == nil || len(g.snapshots.ring) != 60*g.RewindSeconds {
+		g.snapshots = newSnapshots(60 * g.RewindSeconds)
+	}
+	s := g.snapshots.push()
+	s.tick = g.tick
+	s.cameraX = g.cameraX
+	s.cameraY = g.cameraY
+	s.gophers = saveGophers(s.gophers, g.Gopher)
+	s.ghosts = saveGophers(s.ghosts, g.Ghosts)
+}
+
+func saveGophers(states []gopherState, gophers []*Gopher) []gopherState {
+	states = states[:0]
+	for _, gopher := range gophers {
+		var s gopherState
+		if gopher != nil {
+			s.save(gopher)
+		}
+		states = append(states, s)
+	}
+	return states
+}
+
+// rewind moves the run back RewindSeconds, or to its start if it is younger.
+// The gophers revived keep their fitness reported, so they never report it twice.
+func (g *Game) rewind() {
+	if g.snapshots == nil || g.mode != ModeGame {
+		return
+	}
+	s := g.snapshots.back(60 * g.RewindSeconds)
+	if s == nil {
+		return
+	}
+	g.tick = s.tick
+	g.cameraX = s.cameraX
+	g.cameraY = s.cameraY
+	restoreGophers(s.gophers, g.Gopher, s.tick)
+	restoreGophers(s.ghosts, g.Ghosts, s.tick)
+}
+
+func restoreGophers(states []gopherState, gophers []*Gopher, tick int) {
+	for i, gopher := range gophers {
+		if gopher == nil || i >= len(states) {
+			continue
+		}
+		states[i].restore(gopher)
+		if sk, ok := gopher.jumper.(seeker); ok {
+			sk.Seek(tick)
+		}
+	}
+}
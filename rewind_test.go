@@ -0,0 +1,108 @@
+package neatflappy
+
+import (
+	"context"
+	"testing"
+)
+
+// hoverJumper jumps whenever the gopher falls below the middle of the screen
+type hoverJumper struct{}
+
+func (hoverJumper) Jump(in []float64) bool { return in[len(in)-3] > (ScreenHeight/2+300)/600.0 }
+
+func TestSnapshots(t *testing.T) {
+	s := newSnapshots(3)
+	if s.back(1) != nil {
+		t.Error("empty ring rewound")
+	}
+	for i := 0; i < 5; i++ {
+		s.push().tick = i
+	}
+	if s.size != 3 {
+		t.Errorf("unexpected size %d", s.size)
+	}
+	if got := s.back(1); got == nil || got.tick != 4 {
+		t.Errorf("unexpected snapshot %v", got)
+	}
+	if got := s.back(10); got == nil || got.tick != 2 {
+		t.Errorf("unexpected snapshot %v", got)
+	}
+	if s.back(1) != nil {
+		t.Error("rewound past the oldest snapshot")
+	}
+	s.push().tick = 7
+	if got := s.back(1); got == nil || got.tick != 7 {
+		t.Errorf("unexpected snapshot %v", got)
+	}
+}
+
+func TestSeek(t *testing.T) {
+	replay := Replay{Ticks: 10, Jumps: []int{2, 5, 8}}
+	jumper := replay.Jumper()
+	for i := 0; i < 7; i++ {
+		jumper.Jump(nil)
+	}
+	jumper.(seeker).Seek(4)
+	jumps := []int{}
+	for i := 4; i < 10; i++ {
+		if jumper.Jump(nil) {
+			jumps = append(jumps, i)
+		}
+	}
+	if len(jumps) != 2 || jumps[0] != 5 || jumps[1] != 8 {
+		t.Errorf("unexpected jumps after seeking: %v", jumps)
+	}
+
+	recorder := &Recorder{Jumper: replay.Jumper()}
+	for i := 0; i < 7; i++ {
+		recorder.Jump(nil)
+	}
+	recorder.Seek(4)
+	if recorder.Replay.Ticks != 4 || len(recorder.Replay.Jumps) != 1 {
+		t.Errorf("unexpected recording after seeking: %+v", recorder.Replay)
+	}
+}
+
+func TestGame_rewind(t *testing.T) {
+	ctx := context.Background()
+	g := NewGame(100, 1, 2)
+	fitness := make(chan float64, 2)
+	g.Task <- Task{Jumper: neverJumper{}, Fitness: fitness}
+	g.Task <- Task{Jumper: hoverJumper{}, Fitness: make(chan float64, 2)}
+	for g.mode == ModeSetup {
+		if _, err := g.step(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	falling := g.Gopher[0]
+	for !falling.isDead {
+		if _, err := g.step(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.mode != ModeGame {
+		t.Fatal("the hovering gopher died")
+	}
+	died := g.tick
+
+	g.rewind()
+	if falling.isDead {
+		t.Error("the gopher was not revived")
+	}
+	if g.tick != died-60*g.RewindSeconds && g.tick != 0 {
+		t.Errorf("unexpected tick after rewinding: %d", g.tick)
+	}
+
+	for !falling.isDead {
+		if _, err := g.step(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.tick != died {
+		t.Errorf("the gopher died again at tick %d, want %d", g.tick, died)
+	}
+	if len(fitness) != 1 {
+		t.Errorf("the fitness was reported %d times", len(fitness))
+	}
+}
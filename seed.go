@@ -0,0 +1,53 @@
+package neatflappy
+
+import (
+	"errors"
+
+	"github.com/klokare/evo"
+)
+
+var ErrNoSeeds = errors.New("no genomes to seed the population")
+
+// SeededPopulator starts the population from the seed genomes, so an experiment can
+// continue the work of another one. The population built by the wrapped Populator
+// gives the size, the IDs and the species; every seed is copied once and the rest of
+// the population is made of mutated copies of them, in turns.
+type SeededPopulator struct {
+	Populator evo.Populator
+	Mutator   evo.Mutator
+	Seeds     []evo.Genome
+}
+
+// Populate returns the initial population
+func (s SeededPopulator) Populate() (evo.Population, error) {
+	if len(s.Seeds) == 0 {
+		return evo.Population{}, ErrNoSeeds
+	}
+	pop, err := s.Populator.Populate()
+	if err != nil {
+		return pop, err
+	}
+	for i, g := range pop.Genomes {
+		seeded := cloneGenome(s.Seeds[i%len(s.Seeds)])
+		seeded.ID = g.ID
+		seeded.Species = g.Species
+		if i >= len(s.Seeds) {
+			if err := s.Mutator.Mutate(&seeded); err != nil {
+				return pop, err
+			}
+		}
+		pop.Genomes[i] = seeded
+	}
+	return pop, nil
+}
+
+// cloneGenome returns a copy of the encoded genome, ready to be evaluated again
+func cloneGenome(g evo.Genome) evo.Genome {
+	return evo.Genome{
+		Traits: append([]float64{}, g.Traits...),
+		Encoded: evo.Substrate{
+			Nodes: append([]evo.Node{}, g.Encoded.Nodes...),
+			Conns: append([]evo.Conn{}, g.Encoded.Conns...),
+		},
+	}
+}
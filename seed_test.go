@@ -0,0 +1,62 @@
+package neatflappy
+
+import (
+	"testing"
+
+	"github.com/klokare/evo"
+)
+
+type fixedPopulator int
+
+func (n fixedPopulator) Populate() (evo.Population, error) {
+	pop := evo.Population{}
+	for i := 0; i < int(n); i++ {
+		pop.Genomes = append(pop.Genomes, evo.Genome{ID: int64(100 + i), Species: 7})
+	}
+	return pop, nil
+}
+
+// biasMutator increases the bias of every node
+type biasMutator struct{}
+
+func (biasMutator) Mutate(g *evo.Genome) error {
+	for i := range g.Encoded.Nodes {
+		g.Encoded.Nodes[i].Bias++
+	}
+	return nil
+}
+
+func TestSeededPopulator(t *testing.T) {
+	seeds := []evo.Genome{
+		{ID: 1, Species: 1, Fitness: 10, Solved: true, Encoded: evo.Substrate{Nodes: []evo.Node{{Bias: 1}}}},
+		{ID: 2, Species: 2, Fitness: 5, Encoded: evo.Substrate{Nodes: []evo.Node{{Bias: 2}}}},
+	}
+	p := SeededPopulator{Populator: fixedPopulator(5), Mutator: biasMutator{}, Seeds: seeds}
+	pop, err := p.Populate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(pop.Genomes) != 5 {
+		t.Errorf("unexpected population size: %d", len(pop.Genomes))
+		return
+	}
+	for i, g := range pop.Genomes {
+		if g.ID != int64(100+i) || g.Species != 7 || g.Fitness != 0 || g.Solved {
+			t.Errorf("genome %d: unexpected genome %+v", i, g)
+		}
+	}
+	biases := []float64{1, 2, 2, 3, 2}
+	for i, g := range pop.Genomes {
+		if g.Encoded.Nodes[0].Bias != biases[i] {
+			t.Errorf("genome %d: unexpected bias %f", i, g.Encoded.Nodes[0].Bias)
+		}
+	}
+	if seeds[0].Encoded.Nodes[0].Bias != 1 {
+		t.Error("the seeds were mutated")
+	}
+
+	if _, err := (SeededPopulator{Populator: fixedPopulator(5)}).Populate(); err != ErrNoSeeds {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
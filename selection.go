@@ -0,0 +1,125 @@
+package neatflappy
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+// championColor frames the champion of the previous generation
+var championColor = color.RGBA{0xff, 0xd7, 0x00, 0xff}
+
+// speciesColor returns a color for the species, spreading the consecutive IDs
+// around the hue circle so the neighbour species are easy to tell apart
+func speciesColor(species int64) color.Color {
+	const goldenRatio = 0.618033988749895
+	h := math.Mod(float64(species)*goldenRatio, 1) * 6
+	x := 1 - math.Abs(math.Mod(h, 2)-1)
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g, b = 1, x, 0
+	case 1:
+		r, g, b = x, 1, 0
+	case 2:
+		r, g, b = 0, 1, x
+	case 3:
+		r, g, b = 0, x, 1
+	case 4:
+		r, g, b = x, 0, 1
+	default:
+		r, g, b = 1, 0, x
+	}
+	// keep the colors light, so the gopher is still recognizable
+	light := func(v float64) uint8 { return uint8(0xff * (0.4 + 0.6*v)) }
+	return color.RGBA{light(r), light(g), light(b), 0xff}
+}
+
+// color returns the tint of the gopher: the one of its task or, if none, the one of its species
+func (g *Gopher) color() color.Color {
+	if g.tint != nil {
+		return g.tint
+	}
+	return speciesColor(g.species)
+}
+
+// isChampion reports if the gopher flies the best genome of the previous generation
+func (g *Game) isChampion(gopher *Gopher) bool {
+	return g.hud != nil && gopher.ID != 0 && gopher.ID == g.hud.Best
+}
+
+// gopherBounds returns the rectangle of the gopher on the screen
+func (g *Game) gopherBounds(gopher *Gopher) (x, y, w, h int) {
+	return gopher.x16/16 - g.cameraX, gopher.y16/16 - g.cameraY, gopherImageWidth, gopherImageHeight
+}
+
+// gopherAt returns the gopher under the screen position, the living ones first.
+// It returns nil if there is none.
+func (g *Game) gopherAt(px, py int) *Gopher {
+	var found *Gopher
+	for i := len(g.Gopher) - 1; i >= 0; i-- {
+		gopher := g.Gopher[i]
+		if gopher == nil {
+			continue
+		}
+		x, y, w, h := g.gopherBounds(gopher)
+		if px < x || px >= x+w || py < y || py >= y+h {
+			continue
+		}
+		if !gopher.isDead {
+			return gopher
+		}
+		if found == nil {
+			found = gopher
+		}
+	}
+	return found
+}
+
+// drawFrame outlines the gopher
+func (g *Game) drawFrame(screen *ebiten.Image, gopher *Gopher, c color.Color) {
+	x, y, w, h := g.gopherBounds(gopher)
+	x0, y0, x1, y1 := float64(x), float64(y), float64(x+w), float64(y+h)
+	ebitenutil.DrawLine(screen, x0, y0, x1, y0, c)
+	ebitenutil.DrawLine(screen, x1, y0, x1, y1, c)
+	ebitenutil.DrawLine(screen, x1, y1, x0, y1, c)
+	ebitenutil.DrawLine(screen, x0, y1, x0, y0, c)
+}
+
+// drawSelected renders the details of the gopher selected with the right button
+func (g *Game) drawSelected(screen *ebiten.Image) {
+	if g.selected == nil {
+		return
+	}
+	const (
+		lineHeight = 16
+		width      = 200
+		margin     = 8
+	)
+	gopher := g.selected
+	status := "flying"
+	if gopher.isDead {
+		status = "dead"
+	}
+	lines := []string{
+		gopher.Name,
+		fmt.Sprintf("Genome %d", gopher.ID),
+		fmt.Sprintf("Species %d", gopher.species),
+		fmt.Sprintf("Fitness %.2f", gopher.score()),
+		fmt.Sprintf("Jumps %d", gopher.jumps),
+		fmt.Sprintf("Successes %d", gopher.successes),
+		status,
+	}
+	if g.isChampion(gopher) {
+		lines = append(lines, "last champion")
+	}
+	x := ScreenWidth - width - margin
+	y := fontSize + 2*margin
+	ebitenutil.DrawRect(screen, float64(x), float64(y-margin/2), width, float64(len(lines)*lineHeight+margin), color.RGBA{0, 0, 0, 0x80})
+	for i, l := range lines {
+		ebitenutil.DebugPrintAt(screen, l, x+margin, y+i*lineHeight)
+	}
+}
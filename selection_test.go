@@ -0,0 +1,62 @@
+package neatflappy
+
+import (
+	"testing"
+
+	"github.com/klokare/evo"
+)
+
+func TestSpeciesColor(t *testing.T) {
+	for i := int64(0); i < 10; i++ {
+		if speciesColor(i) == speciesColor(i+1) {
+			t.Errorf("species %d and %d share the color", i, i+1)
+		}
+	}
+	if (&Gopher{}).color() != speciesColor(0) {
+		t.Error("the gopher of the species 0 is not tinted")
+	}
+	if (&Gopher{species: 3}).color() != speciesColor(3) {
+		t.Error("the gopher does not take the color of its species")
+	}
+	if (&Gopher{species: 3, tint: championColor}).color() != championColor {
+		t.Error("the tint of the task does not prevail")
+	}
+}
+
+func TestGame_gopherAt(t *testing.T) {
+	g := NewGame(100, 1, 3)
+	for i := range g.Gopher {
+		g.Gopher[i] = NewGopher()
+		g.Gopher[i].init()
+	}
+	g.Gopher[2].isDead = true
+	x, y, _, _ := g.gopherBounds(g.Gopher[0])
+
+	if got := g.gopherAt(x+1, y+1); got != g.Gopher[1] {
+		t.Errorf("unexpected gopher %+v", got)
+	}
+	g.Gopher[0].isDead = true
+	g.Gopher[1].isDead = true
+	if got := g.gopherAt(x+1, y+1); got != g.Gopher[2] {
+		t.Errorf("unexpected gopher %+v", got)
+	}
+	if got := g.gopherAt(x-1, y-1); got != nil {
+		t.Errorf("unexpected gopher %+v", got)
+	}
+}
+
+func TestEvaluator_species(t *testing.T) {
+	e := NewEvaluator(make(chan Task, 1), nil)
+	sub := evo.Substrate{Nodes: []evo.Node{{Neuron: evo.Input}, {Neuron: evo.Output}}}
+	e.PreSearch(evo.Population{Genomes: []evo.Genome{{ID: 1, Species: 4}, {ID: 2, Species: 7, Decoded: sub}}})
+
+	go e.Evaluate(evo.Phenome{ID: 2, Network: firstInput{}})
+	task := <-e.Task
+	if task.Species != 7 {
+		t.Errorf("unexpected species %d", task.Species)
+	}
+	if len(task.Substrate.Nodes) != 2 {
+		t.Errorf("unexpected substrate %+v", task.Substrate)
+	}
+	task.Fitness <- 1
+}
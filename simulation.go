@@ -0,0 +1,59 @@
+package neatflappy
+
+// Outcome summarizes a flight over a level
+type Outcome struct {
+	Level    string
+	Score    float64
+	Distance int
+	Pipes    int
+	Jumps    int
+	Ticks    int
+	Died     bool
+	// TimedOut is set when the flight reaches the tick cap before dying or leaving the level
+	TimedOut bool
+}
+
+// Status reports how the flight ended: "died", "timeout" or "exit"
+func (o Outcome) Status() string {
+	switch {
+	case o.Died:
+		return "died"
+	case o.TimedOut:
+		return "timeout"
+	}
+	return "exit"
+}
+
+// Simulate flies the jumper over the level without rendering anything, following the same
+// rules as Game.Update. The flight ends when the gopher dies, when its score exceeds the exit
+// score of the level or after maxTicks ticks (0 means no limit).
+func Simulate(level Level, jumper Jumper, maxTicks int) Outcome {
+	w := world{level: level, cameraX: -240}
+	gopher := NewGopher()
+	gopher.init()
+	gopher.jumper = jumper
+
+	o := Outcome{Level: level.String()}
+	for {
+		if maxTicks > 0 && o.Ticks >= maxTicks {
+			o.TimedOut = true
+			break
+		}
+		o.Ticks++
+		successed := w.advance()
+		w.update(gopher)
+		o.Died = w.hit(gopher)
+		if o.Died || int(gopher.score()) > level.ExitScore() {
+			break
+		}
+		if successed {
+			gopher.successes++
+		}
+	}
+
+	o.Score = gopher.score()
+	o.Distance = gopher.x16 / 16
+	o.Pipes = gopher.successes
+	o.Jumps = gopher.jumps
+	return o
+}
@@ -0,0 +1,17 @@
+package neatflappy
+
+import "testing"
+
+// neverJumper lets the gopher fall
+type neverJumper struct{}
+
+func (neverJumper) Jump([]float64) bool { return false }
+
+func TestSimulate_status(t *testing.T) {
+	if o := Simulate(Level1(1), neverJumper{}, 0); o.Status() != "died" || o.TimedOut {
+		t.Errorf("unexpected outcome of a falling gopher: %+v", o)
+	}
+	if o := Simulate(Level1(1), neverJumper{}, 5); o.Status() != "timeout" || o.Ticks != 5 || o.Died {
+		t.Errorf("unexpected outcome of a capped flight: %+v", o)
+	}
+}
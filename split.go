@@ -0,0 +1,98 @@
+package neatflappy
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// ErrTooFewGroups is returned when the traces do not fill both sides of the split
+var ErrTooFewGroups = errors.New("not enough groups to split")
+
+// The ways of holding out the validation traces
+const (
+	SplitNone    = "none"
+	SplitRandom  = "random"
+	SplitEpisode = "episode"
+	SplitSession = "session"
+)
+
+// Split holds out a fraction of the dataset for validation. The random mode picks
+// single traces; the episode and session modes keep every life or every recording
+// sitting in the same side, so the validation flights were never seen in training.
+// With SplitNone the whole dataset is used for training and there is no validation.
+func (d *Dataset) Split(mode string, holdout float64, seed int64) (*Dataset, *Dataset, error) {
+	if mode == SplitNone || mode == "" {
+		return d, nil, nil
+	}
+	if holdout <= 0 || holdout >= 1 {
+		return nil, nil, fmt.Errorf("the holdout must be between 0 and 1, got %f", holdout)
+	}
+	rnd := rand.New(rand.NewSource(seed))
+
+	var groups [][]int
+	switch mode {
+	case SplitRandom:
+		for i := range d.Traces {
+			groups = append(groups, []int{i})
+		}
+	case SplitEpisode:
+		groups = d.groups(func(t Trace) [2]int { return [2]int{t.Recording, t.Episode} })
+	case SplitSession:
+		groups = d.groups(func(t Trace) [2]int { return [2]int{t.Recording, 0} })
+	default:
+		return nil, nil, fmt.Errorf("unknown split mode %q", mode)
+	}
+	if len(groups) < 2 {
+		return nil, nil, ErrTooFewGroups
+	}
+	rnd.Shuffle(len(groups), func(i, j int) { groups[i], groups[j] = groups[j], groups[i] })
+
+	target := int(holdout * float64(d.Len()))
+	validation := []int{}
+	k := 0
+	for ; k < len(groups)-1 && (k == 0 || len(validation) < target); k++ {
+		validation = append(validation, groups[k]...)
+	}
+	train := []int{}
+	for _, g := range groups[k:] {
+		train = append(train, g...)
+	}
+	// keep the traces in the recorded order
+	sort.Ints(train)
+	sort.Ints(validation)
+
+	t, err := d.Subset(train)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := d.Subset(validation)
+	return t, v, err
+}
+
+// groups returns the indexes of the traces sharing the same key, in order of appearance
+func (d *Dataset) groups(key func(Trace) [2]int) [][]int {
+	index := map[[2]int]int{}
+	groups := [][]int{}
+	for i, t := range d.Traces {
+		k := key(t)
+		g, ok := index[k]
+		if !ok {
+			g = len(groups)
+			index[k] = g
+			groups = append(groups, []int{})
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// Subset returns a dataset with the selected traces
+func (d *Dataset) Subset(indexes []int) (*Dataset, error) {
+	traces := make([]Trace, len(indexes))
+	for k, i := range indexes {
+		traces[k] = d.Traces[i]
+	}
+	return NewDataset(traces)
+}
@@ -0,0 +1,62 @@
+package neatflappy
+
+import (
+	"testing"
+)
+
+func episodes(n, ticks int) *Dataset {
+	traces := []Trace{}
+	for e := 1; e <= n; e++ {
+		for t := 0; t < ticks; t++ {
+			in := make([]float64, len(SensorNames))
+			in[0] = float64(e)
+			traces = append(traces, Trace{In: in, Out: e%2 == 0, Tick: t, Episode: e, Recording: (e + 1) / 2})
+		}
+	}
+	d, _ := NewDataset(traces)
+	return d
+}
+
+func TestDataset_Split(t *testing.T) {
+	d := episodes(10, 5)
+
+	train, validation, err := d.Split(SplitRandom, 0.2, 1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if train.Len() != 40 || validation.Len() != 10 {
+		t.Errorf("unexpected random split: %d/%d", train.Len(), validation.Len())
+	}
+
+	for _, mode := range []string{SplitEpisode, SplitSession} {
+		train, validation, err := d.Split(mode, 0.3, 1)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if train.Len()+validation.Len() != d.Len() || validation.Len() < 15 || train.Len() == 0 {
+			t.Errorf("%s: unexpected split: %d/%d", mode, train.Len(), validation.Len())
+		}
+		seen := map[int]bool{}
+		for _, tr := range train.Traces {
+			seen[tr.Episode] = true
+		}
+		for _, tr := range validation.Traces {
+			if seen[tr.Episode] {
+				t.Errorf("%s: the episode %d is in both sides", mode, tr.Episode)
+				break
+			}
+		}
+	}
+
+	if train, validation, err := d.Split(SplitNone, 0.2, 1); err != nil || train != d || validation != nil {
+		t.Errorf("unexpected split without holdout: %v %v %v", train, validation, err)
+	}
+	if _, _, err := episodes(1, 5).Split(SplitEpisode, 0.2, 1); err != ErrTooFewGroups {
+		t.Error("expecting an error splitting a single episode")
+	}
+	if _, _, err := d.Split("unknown", 0.2, 1); err == nil {
+		t.Error("expecting an error for an unknown mode")
+	}
+}
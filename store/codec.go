@@ -0,0 +1,186 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SchemaVersion is the version of the records written by this code. Bump it,
+// and register a Migration from the previous version, every time a stored
+// type changes in a way the codecs can not absorb on their own. The backends
+// record it too and migrate their keys when the layout changes with a version.
+const SchemaVersion = 1
+
+// legacyVersion is the version assigned to the raw gob records written before the envelopes
+const legacyVersion = 0
+
+var (
+	ErrUnknownCodec = errors.New("unknown codec")
+	ErrNoMigration  = errors.New("no migration registered")
+
+	// envelopeMagic can not start a gob stream, since gob never emits a zero length message
+	envelopeMagic = []byte{0, 'N', 'F', 'R'}
+)
+
+// Codec serializes the records
+type Codec interface {
+	ID() byte
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// Gob is the default codec
+	Gob Codec = gobCodec{}
+	// JSON is the human readable codec
+	JSON Codec = jsonCodec{}
+)
+
+type gobCodec struct{}
+
+func (gobCodec) ID() byte     { return 1 }
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte     { return 2 }
+func (jsonCodec) Name() string { return "json" }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+var (
+	codecsMu   sync.RWMutex
+	codecs     = map[byte]Codec{1: Gob, 2: JSON}
+	migrations = map[int]Migration{legacyVersion: keepPayload}
+)
+
+// RegisterCodec makes the codec available for decoding the stored records
+func RegisterCodec(c Codec) {
+	codecsMu.Lock()
+	codecs[c.ID()] = c
+	codecsMu.Unlock()
+}
+
+// CodecByName returns the registered codec with the given name
+func CodecByName(name string) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	for _, c := range codecs {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, ErrUnknownCodec
+}
+
+func codecByID(id byte) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	c, ok := codecs[id]
+	if !ok {
+		return nil, ErrUnknownCodec
+	}
+	return c, nil
+}
+
+// Migration upgrades a payload written with the schema version `from` to the
+// next version. The v argument is the value the caller is decoding into, so the
+// migration can tell which kind of record it is dealing with.
+type Migration func(from int, codec Codec, payload []byte, v interface{}) ([]byte, error)
+
+// RegisterMigration sets the migration applied to the records written with the schema version `from`
+func RegisterMigration(from int, m Migration) {
+	codecsMu.Lock()
+	migrations[from] = m
+	codecsMu.Unlock()
+}
+
+func keepPayload(_ int, _ Codec, payload []byte, _ interface{}) ([]byte, error) {
+	return payload, nil
+}
+
+// Marshal encodes v with the codec and wraps it into a versioned envelope:
+// the magic bytes, the schema version (big endian uint16), the codec ID and the payload.
+func Marshal(c Codec, v interface{}) ([]byte, error) {
+	payload, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, len(envelopeMagic)+3, len(envelopeMagic)+3+len(payload))
+	copy(buf, envelopeMagic)
+	binary.BigEndian.PutUint16(buf[len(envelopeMagic):], SchemaVersion)
+	buf[len(envelopeMagic)+2] = c.ID()
+	return append(buf, payload...), nil
+}
+
+// Unmarshal decodes an envelope created by Marshal into v, migrating the payload
+// if it was written by an older schema version. Records without envelope are
+// considered legacy gob payloads.
+func Unmarshal(data []byte, v interface{}) error {
+	version, c, payload, err := open(data)
+	if err != nil {
+		return err
+	}
+	return Upgrade(version, c, payload, v)
+}
+
+// Upgrade runs the registered migrations over a payload written with the given
+// schema version and decodes the result into v
+func Upgrade(version int, c Codec, payload []byte, v interface{}) error {
+	if version > SchemaVersion {
+		return fmt.Errorf("record schema version %d is newer than the supported one (%d)", version, SchemaVersion)
+	}
+	for ; version < SchemaVersion; version++ {
+		codecsMu.RLock()
+		m, ok := migrations[version]
+		codecsMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%s: from version %d", ErrNoMigration.Error(), version)
+		}
+		var err error
+		if payload, err = m(version, c, payload, v); err != nil {
+			return err
+		}
+	}
+	return c.Unmarshal(payload, v)
+}
+
+func open(data []byte) (int, Codec, []byte, error) {
+	if !bytes.HasPrefix(data, envelopeMagic) {
+		return legacyVersion, Gob, data, nil
+	}
+	header := len(envelopeMagic) + 3
+	if len(data) < header {
+		return 0, nil, nil, errors.New("truncated envelope")
+	}
+	c, err := codecByID(data[header-1])
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	version := int(binary.BigEndian.Uint16(data[len(envelopeMagic):]))
+	return version, c, data[header:], nil
+}
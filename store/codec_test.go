@@ -0,0 +1,88 @@
+package store
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+type record struct {
+	Name  string
+	Value int
+}
+
+func TestMarshal(t *testing.T) {
+	for _, c := range []Codec{Gob, JSON} {
+		in := record{Name: "a", Value: 42}
+		data, err := Marshal(c, in)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		out := record{}
+		if err := Unmarshal(data, &out); err != nil {
+			t.Errorf("%s: %s", c.Name(), err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(in, out) {
+			t.Errorf("%s: unexpected record: %+v", c.Name(), out)
+		}
+	}
+}
+
+func TestUnmarshal_legacy(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(record{Name: "old", Value: 1}); err != nil {
+		t.Error(err)
+		return
+	}
+	out := record{}
+	if err := Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Error(err)
+		return
+	}
+	if out.Name != "old" || out.Value != 1 {
+		t.Errorf("unexpected record: %+v", out)
+	}
+}
+
+func TestUnmarshal_migration(t *testing.T) {
+	defer RegisterMigration(legacyVersion, keepPayload)
+
+	RegisterMigration(legacyVersion, func(from int, c Codec, payload []byte, v interface{}) ([]byte, error) {
+		if _, ok := v.(*record); !ok {
+			return payload, nil
+		}
+		old := record{}
+		if err := c.Unmarshal(payload, &old); err != nil {
+			return nil, err
+		}
+		old.Value *= 10
+		return c.Marshal(old)
+	})
+
+	data, _ := Gob.Marshal(record{Name: "old", Value: 1})
+	out := record{}
+	if err := Unmarshal(data, &out); err != nil {
+		t.Error(err)
+		return
+	}
+	if out.Value != 10 {
+		t.Errorf("the migration was not applied: %+v", out)
+	}
+
+	// current records are not migrated
+	data, _ = Marshal(Gob, record{Name: "new", Value: 1})
+	if err := Unmarshal(data, &out); err != nil || out.Value != 1 {
+		t.Errorf("unexpected record: %+v, %v", out, err)
+	}
+}
+
+func TestUnmarshal_unknownCodec(t *testing.T) {
+	data, _ := Marshal(Gob, record{})
+	data[len(envelopeMagic)+2] = 99
+	if err := Unmarshal(data, &record{}); err != ErrUnknownCodec {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
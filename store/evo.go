@@ -0,0 +1,58 @@
+package store
+
+import (
+	"log"
+
+	"github.com/klokare/evo"
+)
+
+// Evo exposes the callbacks for persisting the experiment into a Store
+type Evo struct {
+	Store Store
+	Run   int64
+	// HallOfFame, if set, receives the best genome of the generations improving
+	// the fitness of the run, so the benchmark does not run every generation
+	HallOfFame *HallOfFame
+
+	// submitted is the fitness of the last genome submitted to the HallOfFame, if any
+	submitted    float64
+	hasSubmitted bool
+}
+
+// StoreBest stores the best genome of the population and the summary of the generation
+func (e *Evo) StoreBest(pop evo.Population) error {
+	summary, err := Summarize(e.Run, pop)
+	if err != nil {
+		return err
+	}
+
+	// Output the best
+	best, _ := Best(pop.Genomes)
+
+	log.Printf("generation %d, id %d, species %d, fitness %f, solved %t, complexity %d\n", pop.Generation, best.ID, best.Species, best.Fitness, best.Solved, best.Complexity())
+
+	if err := e.Store.PutGenome(e.Run, best); err != nil {
+		return err
+	}
+	if err := e.Store.PutGeneration(summary); err != nil {
+		return err
+	}
+
+	if e.HallOfFame == nil || (e.hasSubmitted && best.Fitness <= e.submitted) {
+		return nil
+	}
+	e.submitted, e.hasSubmitted = best.Fitness, true
+	rank, err := e.HallOfFame.Submit(e.Run, best)
+	if err != nil {
+		return err
+	}
+	if rank > 0 {
+		log.Printf("genome %d entered the hall of fame at #%d", best.ID, rank)
+	}
+	return nil
+}
+
+// Checkpoint stores the whole population
+func (e *Evo) Checkpoint(pop evo.Population) error {
+	return e.Store.PutPopulation(e.Run, pop)
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"github.com/klokare/evo"
+)
+
+// DefaultHallOfFameSize is the number of genomes kept when the HallOfFame has no size
+const DefaultHallOfFameSize = 10
+
+// LevelScore is the result of a genome flying one of the benchmark levels
+type LevelScore struct {
+	Level    string
+	Score    float64
+	Distance int
+	Pipes    int
+}
+
+// Fame is an entry of the hall of fame
+type Fame struct {
+	Run    int64
+	Genome evo.Genome
+	// Score is the mean score over the benchmark levels, used for the ranking
+	Score  float64
+	Levels []LevelScore
+	Added  time.Time
+}
+
+// HallOfFame keeps the top genomes across every run of the store. Genomes are
+// ranked by their benchmark results, not by their training fitness.
+type HallOfFame struct {
+	Store Store
+	Size  int
+	// Benchmark flies the genome over the fixed suite of levels
+	Benchmark func(evo.Genome) ([]LevelScore, error)
+}
+
+// Submit benchmarks the genome and adds it to the hall of fame if it is good enough.
+// It returns the 1-based rank of the genome or 0 if it did not make the cut.
+func (h *HallOfFame) Submit(runID int64, g evo.Genome) (int, error) {
+	entries, err := h.Store.HallOfFame()
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range entries {
+		if e.Run == runID && e.Genome.ID == g.ID {
+			return i + 1, nil
+		}
+	}
+
+	entry, err := h.evaluate(runID, g)
+	if err != nil {
+		return 0, err
+	}
+
+	entries = h.rank(append(entries, entry))
+	for i, e := range entries {
+		if e.Run == runID && e.Genome.ID == g.ID {
+			return i + 1, h.Store.PutHallOfFame(entries)
+		}
+	}
+	return 0, nil
+}
+
+// Rebuild benchmarks again the current entries and the best genome of every run
+// and replaces the hall of fame with the result. The entries keep the time they
+// were first added.
+func (h *HallOfFame) Rebuild() ([]Fame, error) {
+	candidates, err := h.Store.HallOfFame()
+	if err != nil {
+		return nil, err
+	}
+	runs, err := h.Store.Runs()
+	if err != nil {
+		return nil, err
+	}
+	for _, runID := range runs {
+		best, err := h.Store.BestOfRun(runID)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, Fame{Run: runID, Genome: best})
+	}
+
+	seen := map[[2]int64]bool{}
+	entries := []Fame{}
+	for _, c := range candidates {
+		key := [2]int64{c.Run, c.Genome.ID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		e, err := h.evaluate(c.Run, c.Genome)
+		if err != nil {
+			return nil, err
+		}
+		if !c.Added.IsZero() {
+			e.Added = c.Added
+		}
+		entries = append(entries, e)
+	}
+
+	entries = h.rank(entries)
+	return entries, h.Store.PutHallOfFame(entries)
+}
+
+func (h *HallOfFame) evaluate(runID int64, g evo.Genome) (Fame, error) {
+	levels, err := h.Benchmark(g)
+	if err != nil {
+		return Fame{}, err
+	}
+	total := 0.0
+	for _, l := range levels {
+		total += l.Score
+	}
+	e := Fame{
+		Run:    runID,
+		Genome: g,
+		Levels: levels,
+		Added:  time.Now(),
+	}
+	if len(levels) > 0 {
+		e.Score = total / float64(len(levels))
+	}
+	return e, nil
+}
+
+func (h *HallOfFame) rank(entries []Fame) []Fame {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Score > entries[j].Score })
+	size := h.Size
+	if size <= 0 {
+		size = DefaultHallOfFameSize
+	}
+	if len(entries) > size {
+		entries = entries[:size]
+	}
+	return entries
+}
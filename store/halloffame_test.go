@@ -0,0 +1,93 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/klokare/evo"
+)
+
+func TestHallOfFame(t *testing.T) {
+	s := NewMemory()
+	run, _ := s.NewRun()
+
+	evaluations := 0
+	h := HallOfFame{
+		Store: s,
+		Size:  2,
+		// the benchmark ignores the training fitness and prefers the lower IDs
+		Benchmark: func(g evo.Genome) ([]LevelScore, error) {
+			evaluations++
+			return []LevelScore{{Score: 100 - float64(g.ID)}, {Score: 100 - float64(g.ID)}}, nil
+		},
+	}
+
+	for _, tc := range []struct {
+		id   int64
+		rank int
+	}{
+		{id: 5, rank: 1},
+		{id: 9, rank: 2},
+		{id: 1, rank: 1},
+		{id: 20, rank: 0},
+		{id: 1, rank: 1},
+	} {
+		rank, err := h.Submit(run, evo.Genome{ID: tc.id, Fitness: float64(tc.id)})
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if rank != tc.rank {
+			t.Errorf("genome %d: unexpected rank %d, want %d", tc.id, rank, tc.rank)
+		}
+	}
+
+	if evaluations != 4 {
+		t.Errorf("the known genomes should not be evaluated again: %d evaluations", evaluations)
+	}
+
+	entries, _ := s.HallOfFame()
+	if len(entries) != 2 || entries[0].Genome.ID != 1 || entries[1].Genome.ID != 5 || entries[0].Score != 99 {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	added := entries[0].Added
+	s.PutGenome(run, evo.Genome{ID: 0})
+	entries, err := h.Rebuild()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(entries) != 2 || entries[0].Genome.ID != 0 || entries[1].Genome.ID != 1 {
+		t.Errorf("unexpected entries after rebuilding: %+v", entries)
+	}
+	if !entries[1].Added.Equal(added) {
+		t.Errorf("the rebuild changed when genome 1 was added: %v, want %v", entries[1].Added, added)
+	}
+}
+
+func TestEvo_hallOfFame(t *testing.T) {
+	s := NewMemory()
+	run, _ := s.NewRun()
+
+	submitted := []int64{}
+	e := Evo{
+		Store: s,
+		Run:   run,
+		HallOfFame: &HallOfFame{
+			Store: s,
+			Benchmark: func(g evo.Genome) ([]LevelScore, error) {
+				submitted = append(submitted, g.ID)
+				return []LevelScore{{Score: g.Fitness}}, nil
+			},
+		},
+	}
+	for i, f := range []float64{0, 0, 3, 2, 3, 4} {
+		pop := evo.Population{Generation: i, Genomes: []evo.Genome{{ID: int64(i + 1), Fitness: f}}}
+		if err := e.StoreBest(pop); err != nil {
+			t.Error(err)
+		}
+	}
+	if len(submitted) != 3 || submitted[0] != 1 || submitted[1] != 3 || submitted[2] != 6 {
+		t.Errorf("unexpected genomes submitted: %v", submitted)
+	}
+}
@@ -0,0 +1,280 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klokare/evo"
+)
+
+const (
+	runsFile        = "runs.jsonl"
+	genomesFile     = "genomes.jsonl"
+	generationsFile = "generations.jsonl"
+	populationsFile = "populations.jsonl"
+	hallOfFameFile  = "halloffame.jsonl"
+)
+
+// NewJSONLines returns a store persisting the records as JSON lines under dir.
+// Every run gets its own subdirectory, so the files are easy to diff and grep:
+//
+//	dir/runs.jsonl
+//	dir/halloffame.jsonl
+//	dir/000001/genomes.jsonl
+//	dir/000001/generations.jsonl
+//	dir/000001/populations.jsonl
+//
+// Records are only appended. When a key is stored twice, the last line wins.
+// Every line is an envelope holding the schema version and the record itself:
+//
+//	{"Version":1,"Record":{...}}
+func NewJSONLines(dir string) (*JSONLines, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &JSONLines{dir: dir}, nil
+}
+
+// JSONLines is a Store backed by a directory of JSON-lines files
+type JSONLines struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func (j *JSONLines) NewRun() (int64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	runs, err := j.runs()
+	if err != nil {
+		return 0, err
+	}
+	id := int64(1)
+	if len(runs) > 0 {
+		id = runs[len(runs)-1] + 1
+	}
+	if err := os.MkdirAll(j.runDir(id), 0755); err != nil {
+		return 0, err
+	}
+	return id, j.append(filepath.Join(j.dir, runsFile), Run{ID: id, Started: time.Now()})
+}
+
+func (j *JSONLines) Runs() ([]int64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.runs()
+}
+
+func (j *JSONLines) runs() ([]int64, error) {
+	runs := []int64{}
+	err := j.read(filepath.Join(j.dir, runsFile), func(decode func(interface{}) error) error {
+		r := Run{}
+		if err := decode(&r); err != nil {
+			return err
+		}
+		runs = append(runs, r.ID)
+		return nil
+	})
+	return runs, err
+}
+
+func (j *JSONLines) PutGenome(runID int64, g evo.Genome) error {
+	return j.put(runID, genomesFile, g)
+}
+
+func (j *JSONLines) Genome(runID, id int64) (evo.Genome, error) {
+	genomes, err := j.Genomes(runID)
+	if err != nil {
+		return evo.Genome{}, err
+	}
+	for _, g := range genomes {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+	return evo.Genome{}, ErrNotFound
+}
+
+func (j *JSONLines) Genomes(runID int64) ([]evo.Genome, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	index := map[int64]evo.Genome{}
+	err := j.read(filepath.Join(j.runDir(runID), genomesFile), func(decode func(interface{}) error) error {
+		g := evo.Genome{}
+		if err := decode(&g); err != nil {
+			return err
+		}
+		index[g.ID] = g
+		return nil
+	})
+
+	genomes := make([]evo.Genome, 0, len(index))
+	for _, g := range index {
+		genomes = append(genomes, g)
+	}
+	sort.Slice(genomes, func(i, j int) bool { return genomes[i].ID < genomes[j].ID })
+	return genomes, err
+}
+
+func (j *JSONLines) BestOfRun(runID int64) (evo.Genome, error) {
+	genomes, err := j.Genomes(runID)
+	if err != nil {
+		return evo.Genome{}, err
+	}
+	return Best(genomes)
+}
+
+func (j *JSONLines) PutGeneration(g Generation) error {
+	return j.put(g.Run, generationsFile, g)
+}
+
+func (j *JSONLines) Generations(runID int64) ([]Generation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	index := map[int]Generation{}
+	err := j.read(filepath.Join(j.runDir(runID), generationsFile), func(decode func(interface{}) error) error {
+		g := Generation{}
+		if err := decode(&g); err != nil {
+			return err
+		}
+		index[g.Generation] = g
+		return nil
+	})
+
+	generations := make([]Generation, 0, len(index))
+	for _, g := range index {
+		generations = append(generations, g)
+	}
+	sort.Slice(generations, func(i, j int) bool { return generations[i].Generation < generations[j].Generation })
+	return generations, err
+}
+
+func (j *JSONLines) PutPopulation(runID int64, pop evo.Population) error {
+	return j.put(runID, populationsFile, pop)
+}
+
+func (j *JSONLines) Population(runID int64, generation int) (evo.Population, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	found := false
+	res := evo.Population{}
+	err := j.read(filepath.Join(j.runDir(runID), populationsFile), func(decode func(interface{}) error) error {
+		pop := evo.Population{}
+		if err := decode(&pop); err != nil {
+			return err
+		}
+		if pop.Generation == generation {
+			res = pop
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+	if !found {
+		return res, ErrNotFound
+	}
+	return res, nil
+}
+
+// PutHallOfFame appends a snapshot of the whole hall of fame, so its history is kept
+func (j *JSONLines) PutHallOfFame(entries []Fame) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.append(filepath.Join(j.dir, hallOfFameFile), entries)
+}
+
+func (j *JSONLines) HallOfFame() ([]Fame, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := []Fame{}
+	err := j.read(filepath.Join(j.dir, hallOfFameFile), func(decode func(interface{}) error) error {
+		entries = []Fame{}
+		return decode(&entries)
+	})
+	return entries, err
+}
+
+func (j *JSONLines) Close() error {
+	return nil
+}
+
+func (j *JSONLines) runDir(runID int64) string {
+	return filepath.Join(j.dir, fmt.Sprintf("%06d", runID))
+}
+
+func (j *JSONLines) put(runID int64, name string, v interface{}) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(j.runDir(runID), 0755); err != nil {
+		return err
+	}
+	return j.append(filepath.Join(j.runDir(runID), name), v)
+}
+
+// jsonRecord is the envelope of every line
+type jsonRecord struct {
+	Version int
+	Record  json.RawMessage
+}
+
+func (j *JSONLines) append(path string, v interface{}) error {
+	record, err := JSON.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(jsonRecord{Version: SchemaVersion, Record: record}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// read calls fn with a decoder for every line until the file is consumed. fn can
+// skip a record by not calling the decoder. Missing files are considered empty.
+func (j *JSONLines) read(path string, fn func(decode func(interface{}) error) error) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for line := 1; dec.More(); line++ {
+		record := jsonRecord{}
+		if err := dec.Decode(&record); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("reading %s, record %d: %s", path, line, err.Error())
+		}
+		decode := func(v interface{}) error {
+			return Upgrade(record.Version, JSON, record.Record, v)
+		}
+		if err := fn(decode); err != nil {
+			return fmt.Errorf("reading %s, record %d: %s", path, line, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klokare/evo"
+)
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		genomes:     map[int64]map[int64]evo.Genome{},
+		generations: map[int64]map[int]Generation{},
+		populations: map[int64]map[int]evo.Population{},
+	}
+}
+
+// Memory is a Store keeping everything in memory. It is meant for tests and short-lived tools.
+type Memory struct {
+	mu          sync.RWMutex
+	runs        []Run
+	genomes     map[int64]map[int64]evo.Genome
+	generations map[int64]map[int]Generation
+	populations map[int64]map[int]evo.Population
+	hallOfFame  []Fame
+}
+
+func (m *Memory) NewRun() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := int64(len(m.runs) + 1)
+	m.runs = append(m.runs, Run{ID: id, Started: time.Now()})
+	return id, nil
+}
+
+func (m *Memory) Runs() ([]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	runs := make([]int64, len(m.runs))
+	for i, r := range m.runs {
+		runs[i] = r.ID
+	}
+	return runs, nil
+}
+
+func (m *Memory) PutGenome(runID int64, g evo.Genome) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.genomes[runID] == nil {
+		m.genomes[runID] = map[int64]evo.Genome{}
+	}
+	m.genomes[runID][g.ID] = g
+	return nil
+}
+
+func (m *Memory) Genome(runID, id int64) (evo.Genome, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	g, ok := m.genomes[runID][id]
+	if !ok {
+		return evo.Genome{}, ErrNotFound
+	}
+	return g, nil
+}
+
+func (m *Memory) Genomes(runID int64) ([]evo.Genome, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	genomes := []evo.Genome{}
+	for _, g := range m.genomes[runID] {
+		genomes = append(genomes, g)
+	}
+	sort.Slice(genomes, func(i, j int) bool { return genomes[i].ID < genomes[j].ID })
+	return genomes, nil
+}
+
+func (m *Memory) BestOfRun(runID int64) (evo.Genome, error) {
+	genomes, err := m.Genomes(runID)
+	if err != nil {
+		return evo.Genome{}, err
+	}
+	return Best(genomes)
+}
+
+func (m *Memory) PutGeneration(g Generation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.generations[g.Run] == nil {
+		m.generations[g.Run] = map[int]Generation{}
+	}
+	m.generations[g.Run][g.Generation] = g
+	return nil
+}
+
+func (m *Memory) Generations(runID int64) ([]Generation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	generations := []Generation{}
+	for _, g := range m.generations[runID] {
+		generations = append(generations, g)
+	}
+	sort.Slice(generations, func(i, j int) bool { return generations[i].Generation < generations[j].Generation })
+	return generations, nil
+}
+
+func (m *Memory) PutPopulation(runID int64, pop evo.Population) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.populations[runID] == nil {
+		m.populations[runID] = map[int]evo.Population{}
+	}
+	m.populations[runID][pop.Generation] = pop
+	return nil
+}
+
+func (m *Memory) Population(runID int64, generation int) (evo.Population, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pop, ok := m.populations[runID][generation]
+	if !ok {
+		return evo.Population{}, ErrNotFound
+	}
+	return pop, nil
+}
+
+func (m *Memory) PutHallOfFame(entries []Fame) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hallOfFame = append([]Fame{}, entries...)
+	return nil
+}
+
+func (m *Memory) HallOfFame() ([]Fame, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]Fame{}, m.hallOfFame...), nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
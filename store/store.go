@@ -0,0 +1,117 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/klokare/evo"
+)
+
+// ErrNotFound is returned when the requested record is not stored
+var ErrNotFound = errors.New("not found")
+
+// Store persists the results of the experiments, grouped by run
+type Store interface {
+	// NewRun registers a new run and returns its ID
+	NewRun() (int64, error)
+	// Runs returns the IDs of all the registered runs
+	Runs() ([]int64, error)
+
+	// PutGenome stores the genome as part of the run
+	PutGenome(runID int64, g evo.Genome) error
+	// Genome returns the genome id stored for the run
+	Genome(runID, id int64) (evo.Genome, error)
+	// Genomes returns all the genomes stored for the run, sorted by ID
+	Genomes(runID int64) ([]evo.Genome, error)
+	// BestOfRun returns the best genome stored for the run
+	BestOfRun(runID int64) (evo.Genome, error)
+
+	// PutGeneration stores the summary of a generation
+	PutGeneration(g Generation) error
+	// Generations returns the summaries stored for the run, sorted by generation
+	Generations(runID int64) ([]Generation, error)
+
+	// PutPopulation stores a checkpoint of the whole population
+	PutPopulation(runID int64, pop evo.Population) error
+	// Population returns the checkpoint of the given generation
+	Population(runID int64, generation int) (evo.Population, error)
+
+	// PutHallOfFame replaces the hall of fame with the given entries
+	PutHallOfFame(entries []Fame) error
+	// HallOfFame returns the entries of the hall of fame, best first
+	HallOfFame() ([]Fame, error)
+
+	Close() error
+}
+
+// Run describes a single execution of an experiment
+type Run struct {
+	ID      int64
+	Started time.Time
+}
+
+// Generation summarizes an evaluated population of a run
+type Generation struct {
+	Run         int64
+	Generation  int
+	Best        int64
+	Species     int
+	Fitness     float64
+	MeanFitness float64
+	Solved      bool
+	Complexity  int
+}
+
+// Best returns the best genome of the list, using the same criteria as Evo.StoreBest.
+// The received slice is not modified.
+func Best(genomes []evo.Genome) (evo.Genome, error) {
+	if len(genomes) == 0 {
+		return evo.Genome{}, ErrNotFound
+	}
+
+	// Copy the genomes so we can sort them without affecting the caller
+	sorted := make([]evo.Genome, len(genomes))
+	copy(sorted, genomes)
+
+	// Sort so the best genome is at the end
+	evo.SortBy(sorted, evo.BySolved, evo.ByFitness, evo.ByComplexity, evo.ByAge)
+
+	return sorted[len(sorted)-1], nil
+}
+
+// Top returns the n best genomes, the best first, ranked as Best does
+func Top(genomes []evo.Genome, n int) []evo.Genome {
+	sorted := make([]evo.Genome, len(genomes))
+	copy(sorted, genomes)
+	evo.SortBy(sorted, evo.BySolved, evo.ByFitness, evo.ByComplexity, evo.ByAge)
+
+	top := []evo.Genome{}
+	for i := len(sorted) - 1; i >= 0 && len(top) < n; i-- {
+		top = append(top, sorted[i])
+	}
+	return top
+}
+
+// Summarize builds the generation summary of the population
+func Summarize(runID int64, pop evo.Population) (Generation, error) {
+	best, err := Best(pop.Genomes)
+	if err != nil {
+		return Generation{}, err
+	}
+
+	total := 0.0
+	for _, g := range pop.Genomes {
+		total += g.Fitness
+	}
+
+	return Generation{
+		Run:         runID,
+		Generation:  pop.Generation,
+		Best:        best.ID,
+		Species:     len(pop.Species),
+		Fitness:     best.Fitness,
+		MeanFitness: total / float64(len(pop.Genomes)),
+		Solved:      best.Solved,
+		Complexity:  best.Complexity(),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klokare/evo"
+)
+
+func TestMemory(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func TestJSONLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neatflappy-store")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewJSONLines(dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	testStore(t, s)
+
+	// a fresh store over the same directory sees the same records
+	reopened, _ := NewJSONLines(dir)
+	if runs, err := reopened.Runs(); err != nil || len(runs) != 2 {
+		t.Errorf("unexpected runs after reopening: %v, %v", runs, err)
+	}
+}
+
+func TestJSONLines_skip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neatflappy-store")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	s, _ := NewJSONLines(dir)
+	for i := 0; i < 3; i++ {
+		s.NewRun()
+	}
+	records := 0
+	err = s.read(filepath.Join(dir, runsFile), func(func(interface{}) error) error {
+		records++
+		return nil
+	})
+	if err != nil || records != 3 {
+		t.Errorf("unexpected records: %d, %v", records, err)
+	}
+}
+
+func testStore(t *testing.T, s Store) {
+	defer s.Close()
+
+	run1, err := s.NewRun()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	run2, _ := s.NewRun()
+	if runs, err := s.Runs(); err != nil || !reflect.DeepEqual(runs, []int64{run1, run2}) {
+		t.Errorf("unexpected runs: %v, %v", runs, err)
+	}
+
+	if _, err := s.Genome(run1, 1); err != ErrNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	pop := evo.Population{Generation: 3}
+	for i := int64(3); i > 0; i-- {
+		g := evo.Genome{ID: i, Fitness: float64(i)}
+		pop.Genomes = append(pop.Genomes, g)
+		if err := s.PutGenome(run1, g); err != nil {
+			t.Error(err)
+		}
+	}
+	s.PutGenome(run2, evo.Genome{ID: 42})
+
+	g, err := s.Genome(run1, 2)
+	if err != nil || g.Fitness != 2 {
+		t.Errorf("unexpected genome: %+v, %v", g, err)
+	}
+	genomes, err := s.Genomes(run1)
+	if err != nil || len(genomes) != 3 || genomes[0].ID != 1 {
+		t.Errorf("unexpected genomes: %+v, %v", genomes, err)
+	}
+
+	e := Evo{Store: s, Run: run2}
+	if err := e.StoreBest(pop); err != nil {
+		t.Error(err)
+	}
+	if err := e.Checkpoint(pop); err != nil {
+		t.Error(err)
+	}
+
+	generations, err := s.Generations(run2)
+	if err != nil || len(generations) != 1 || generations[0].Generation != 3 || generations[0].MeanFitness != 2 {
+		t.Errorf("unexpected generations: %+v, %v", generations, err)
+	}
+	if generations, _ := s.Generations(run1); len(generations) != 0 {
+		t.Errorf("unexpected generations: %+v", generations)
+	}
+
+	checkpoint, err := s.Population(run2, 3)
+	if err != nil || len(checkpoint.Genomes) != 3 {
+		t.Errorf("unexpected checkpoint: %+v, %v", checkpoint, err)
+	}
+	if _, err := s.Population(run2, 4); err != ErrNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
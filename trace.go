@@ -0,0 +1,225 @@
+package neatflappy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// TraceVersion is the version of the trace logs written by this package. The logs
+// without a header are considered version 1.
+const TraceVersion = 2
+
+// A trace log is a JSON document per line. It starts with a header, followed by the
+// recorded sessions: a session header, the traces of every tick and the end of the episode.
+//
+//	{"Header":{"Version":2,"Sensors":["pipe1 dx",...],"Physics":{...},"Level":0,"Seed":0,"Recorder":"human"}}
+//	{"Session":{"ID":1,"Level":0,"Seed":0,"Started":"..."}}
+//	{"In":[...],"Out":false,"Tick":0,"Episode":1}
+//	...
+//	{"End":{"Episode":1,"Ticks":512,"Fitness":1234.5,"Status":"died"}}
+
+// Trace is a decision of the gopher: the inputs it sensed and whether it jumped
+type Trace struct {
+	In      []float64
+	Out     bool
+	Tick    int
+	Episode int
+	// Recording is the number of headers found before the trace by LoadTraces
+	Recording int `json:"-"`
+}
+
+// Physics are the parameters of the flight the traces were recorded with
+type Physics struct {
+	Speed        int
+	JumpVelocity int
+	Gravity      int
+	MaxVelocity  int
+}
+
+// DefaultPhysics are the physics of the game
+var DefaultPhysics = Physics{
+	Speed:        scrollSpeed16,
+	JumpVelocity: jumpVelocity16,
+	Gravity:      gravity16,
+	MaxVelocity:  maxVelocity16,
+}
+
+// TraceHeader describes how the traces of a log were recorded
+type TraceHeader struct {
+	Version  int
+	Sensors  []string
+	Physics  Physics
+	Level    int
+	Seed     int64
+	Recorder string
+}
+
+// NewTraceHeader returns the header for the traces recorded by this version of the game
+func NewTraceHeader(recorder string, level int, seed int64) TraceHeader {
+	return TraceHeader{
+		Version:  TraceVersion,
+		Sensors:  SensorNames,
+		Physics:  DefaultPhysics,
+		Level:    level,
+		Seed:     seed,
+		Recorder: recorder,
+	}
+}
+
+// Columns returns, for every current sensor, the position of its input in the traces
+// described by the header. It fails if the traces can not be adapted to the current layout.
+func (h TraceHeader) Columns() ([]int, error) {
+	if h.Version > TraceVersion {
+		return nil, fmt.Errorf("unsupported trace version %d", h.Version)
+	}
+	if h.Physics != DefaultPhysics {
+		return nil, fmt.Errorf("traces recorded with different physics: %+v", h.Physics)
+	}
+	positions := map[string]int{}
+	for i, name := range h.Sensors {
+		positions[name] = i
+	}
+	columns := make([]int, len(SensorNames))
+	for i, name := range SensorNames {
+		k, ok := positions[name]
+		if !ok {
+			return nil, fmt.Errorf("the traces do not record the sensor %q", name)
+		}
+		columns[i] = k
+	}
+	return columns, nil
+}
+
+// Compatible reports whether the traces of both headers can be mixed in the same log
+func (h TraceHeader) Compatible(other TraceHeader) bool {
+	return h.Version == other.Version && h.Physics == other.Physics && reflect.DeepEqual(h.Sensors, other.Sensors)
+}
+
+// Session is the header written to the trace log before every recorded life
+type Session struct {
+	ID      int
+	Level   int
+	Seed    int64
+	Started time.Time
+}
+
+// EpisodeEnd closes the traces of a session with its outcome
+type EpisodeEnd struct {
+	Episode int
+	Ticks   int
+	Fitness float64
+	// Status tells how the episode ended, as Outcome.Status does. The logs recorded
+	// before it was added leave it empty.
+	Status string
+}
+
+// logRecord is a line of a trace log: a header, the start or the end of a session, or a trace
+type logRecord struct {
+	Trace
+	Header  *TraceHeader
+	Session *Session
+	End     *EpisodeEnd
+}
+
+func (r logRecord) isTrace() bool {
+	return r.Header == nil && r.Session == nil && r.End == nil
+}
+
+// WriteTraceHeader writes the header of the trace log
+func WriteTraceHeader(w io.Writer, h TraceHeader) error {
+	return json.NewEncoder(w).Encode(struct{ Header TraceHeader }{h})
+}
+
+// WriteSession writes the session header to the trace log
+func WriteSession(w io.Writer, s Session) error {
+	return json.NewEncoder(w).Encode(struct{ Session Session }{s})
+}
+
+// WriteEpisodeEnd writes the outcome of the session to the trace log
+func WriteEpisodeEnd(w io.Writer, e EpisodeEnd) error {
+	return json.NewEncoder(w).Encode(struct{ End EpisodeEnd }{e})
+}
+
+// ReadTraceHeader returns the header of the trace log, if it has one
+func ReadTraceHeader(r io.Reader) (TraceHeader, bool, error) {
+	record := logRecord{}
+	err := json.NewDecoder(r).Decode(&record)
+	if err == io.EOF {
+		return TraceHeader{}, false, nil
+	}
+	if err != nil || record.Header == nil {
+		return TraceHeader{}, false, err
+	}
+	return *record.Header, true, nil
+}
+
+// LogSessions returns the headers of the sessions recorded in the trace log
+func LogSessions(r io.Reader) ([]Session, error) {
+	sessions := []Session{}
+	decoder := json.NewDecoder(r)
+	for {
+		record := logRecord{}
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return sessions, nil
+		}
+		if err != nil {
+			return sessions, err
+		}
+		if record.Session != nil {
+			sessions = append(sessions, *record.Session)
+		}
+	}
+}
+
+// WriteTraceLog writes the traces as a trace log keeping what the meta records about
+// them. Every recording starts with its header and every episode with its session,
+// and ends with its outcome if known. The headers declare the current layout, the
+// one of the loaded traces.
+func WriteTraceLog(w io.Writer, traces []Trace, meta LogMeta) error {
+	enc := json.NewEncoder(w)
+	end := func(episode int) error {
+		if e, ok := meta.Ends[episode]; ok {
+			return WriteEpisodeEnd(w, e)
+		}
+		return nil
+	}
+	header := NewTraceHeader("traces", 0, 0)
+	for i, t := range traces {
+		recording := i == 0 || t.Recording != traces[i-1].Recording
+		episode := recording || t.Episode != traces[i-1].Episode
+		if i > 0 && episode {
+			if err := end(traces[i-1].Episode); err != nil {
+				return err
+			}
+		}
+		if recording {
+			header = NewTraceHeader("traces", 0, 0)
+			if h, ok := meta.Headers[t.Recording]; ok {
+				header = NewTraceHeader(h.Recorder, h.Level, h.Seed)
+			}
+			if err := WriteTraceHeader(w, header); err != nil {
+				return err
+			}
+		}
+		if episode {
+			s, ok := meta.Sessions[t.Episode]
+			if !ok {
+				s = Session{ID: t.Episode, Level: header.Level, Seed: header.Seed}
+			}
+			if err := WriteSession(w, s); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(t); err != nil {
+			return err
+		}
+	}
+	if len(traces) > 0 {
+		return end(traces[len(traces)-1].Episode)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package neatflappy
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLoadTraces_header(t *testing.T) {
+	header := NewTraceHeader("test", 1, 42)
+	// the recorder sensed the inputs in the reverse order
+	header.Sensors = make([]string, len(SensorNames))
+	for i, name := range SensorNames {
+		header.Sensors[len(SensorNames)-1-i] = name
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteTraceHeader(buf, header); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := WriteSession(buf, Session{ID: 1}); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := json.NewEncoder(buf).Encode(Trace{In: []float64{6, 5, 4, 3, 2, 1, 0}, Out: true, Episode: 1}); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := WriteEpisodeEnd(buf, EpisodeEnd{Episode: 1, Ticks: 1, Fitness: 2}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	h, ok, err := ReadTraceHeader(bytes.NewReader(buf.Bytes()))
+	if err != nil || !ok || h.Recorder != "test" || h.Seed != 42 || !h.Compatible(header) {
+		t.Errorf("unexpected header: %+v %v %v", h, ok, err)
+	}
+
+	samples, err := LoadTraces(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(samples) != 1 || !reflect.DeepEqual(samples[0].In, []float64{0, 1, 2, 3, 4, 5, 6}) || samples[0].Episode != 1 {
+		t.Errorf("unexpected samples: %+v", samples)
+	}
+}
+
+func TestLoadTraces_mismatch(t *testing.T) {
+	if _, err := LoadTraces(bytes.NewBufferString(legacySampleData)); err == nil {
+		t.Error("expecting an error for the legacy input layout")
+	}
+
+	header := NewTraceHeader("test", 0, 0)
+	header.Physics.Gravity++
+	buf := new(bytes.Buffer)
+	if err := WriteTraceHeader(buf, header); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := LoadTraces(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("expecting an error for different physics")
+	}
+
+	header = NewTraceHeader("test", 0, 0)
+	header.Sensors = header.Sensors[1:]
+	buf.Reset()
+	if err := WriteTraceHeader(buf, header); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := LoadTraces(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Error("expecting an error for a missing sensor")
+	}
+}
@@ -0,0 +1,132 @@
+package neatflappy
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/kpacha/neatflappy/champion"
+)
+
+var (
+	positiveWeightColor = color.RGBA{0x24, 0x71, 0xa3, 0xff}
+	negativeWeightColor = color.RGBA{0xc0, 0x39, 0x2b, 0xff}
+)
+
+// networkOf returns the network driving the gopher, translating its substrate the
+// first time. It returns nil if the jumper is not a known network. The translation
+// is only drawn: the gopher flies the network of its jumper, decoded as the one
+// of the training, which the champion package reproduces node by node.
+func networkOf(gopher *Gopher) *champion.Network {
+	if gopher.network != nil {
+		return gopher.network
+	}
+	switch j := gopher.jumper.(type) {
+	case *champion.Network:
+		gopher.network = j
+	case *evoJumper:
+		if n, ok := j.p.Network.(*champion.Network); ok {
+			gopher.network = n
+		}
+	}
+	if gopher.network == nil && len(gopher.substrate.Nodes) > 0 {
+		n, err := champion.FromSubstrate(gopher.substrate)
+		if err != nil {
+			return nil
+		}
+		n.Inputs = SensorNames
+		gopher.network = n
+	}
+	return gopher.network
+}
+
+// watched returns the gopher shown by the network panel: the selected one or, if
+// none, the one with the best score still flying
+func (g *Game) watched() *Gopher {
+	if g.selected != nil {
+		return g.selected
+	}
+	var best *Gopher
+	for _, gopher := range g.Gopher {
+		if gopher == nil || gopher.isDead {
+			continue
+		}
+		if best == nil || gopher.score() > best.score() {
+			best = gopher
+		}
+	}
+	return best
+}
+
+// activationColor maps the value of a node to green if positive and red if negative,
+// brighter the stronger it is
+func activationColor(v float64) color.Color {
+	i := uint8(0x40 + 0xbf*math.Min(math.Abs(v), 1))
+	if v < 0 {
+		return color.RGBA{i, 0x20, 0x20, 0xff}
+	}
+	return color.RGBA{0x20, i, 0x20, 0xff}
+}
+
+// drawNetwork renders the topology of the network of the watched gopher, with the
+// activation of the nodes for the inputs of its last tick. The thicker the edge,
+// the heavier its weight.
+func (g *Game) drawNetwork(screen *ebiten.Image) {
+	if !g.ShowNetwork || g.mode == ModeSetup {
+		return
+	}
+	gopher := g.watched()
+	if gopher == nil {
+		return
+	}
+	net := networkOf(gopher)
+	if net == nil {
+		return
+	}
+	const (
+		width      = 280
+		height     = 180
+		labelWidth = 60
+		valueWidth = 36
+		margin     = 12
+		nodeSize   = 6
+	)
+	x0 := float64(ScreenWidth - width - margin)
+	y0 := float64(ScreenHeight - tileSize - margin - height)
+	ebitenutil.DrawRect(screen, x0, y0, width, height, color.RGBA{0, 0, 0, 0x80})
+
+	points := net.Layout(width-labelWidth-valueWidth, height-margin, margin)
+	for i := range points {
+		points[i].X += x0 + labelWidth
+		points[i].Y += y0 + margin
+	}
+	values := net.Values(gopher.inputs)
+
+	for _, c := range net.Conns {
+		if !c.Enabled {
+			continue
+		}
+		col := positiveWeightColor
+		if c.Weight < 0 {
+			col = negativeWeightColor
+		}
+		from, to := points[c.Source], points[c.Target]
+		for k := 0; k < 1+int(math.Min(math.Abs(c.Weight), 4)); k++ {
+			ebitenutil.DrawLine(screen, from.X, from.Y+float64(k), to.X, to.Y+float64(k), col)
+		}
+	}
+
+	for i, node := range net.Nodes {
+		p := points[i]
+		ebitenutil.DrawRect(screen, p.X-nodeSize/2, p.Y-nodeSize/2, nodeSize, nodeSize, activationColor(values[i]))
+		switch node.Kind {
+		case champion.KindInput:
+			ebitenutil.DebugPrintAt(screen, net.Label(i), int(x0)+4, int(p.Y)-8)
+		case champion.KindOutput:
+			ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%.2f", values[i]), int(p.X)+nodeSize, int(p.Y)-8)
+		}
+	}
+	ebitenutil.DebugPrintAt(screen, gopher.Name, int(x0)+4, int(y0))
+}
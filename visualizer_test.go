@@ -0,0 +1,62 @@
+package neatflappy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klokare/evo"
+	"github.com/kpacha/neatflappy/champion"
+)
+
+// altitudeNetwork jumps when the altitude input is above 0.5
+const altitudeNetwork = `{
+	"format": 1,
+	"id": 7,
+	"nodes": [
+		{"id": 0, "kind": "input", "activation": "direct", "layer": 0, "x": 0},
+		{"id": 1, "kind": "output", "activation": "step", "bias": -0.5, "layer": 1, "x": 0}
+	],
+	"conns": [
+		{"source": 0, "target": 1, "weight": 1, "enabled": true}
+	]
+}`
+
+func TestNetworkOf(t *testing.T) {
+	net, err := champion.Load(strings.NewReader(altitudeNetwork))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, jumper := range []Jumper{net, NewPhenomeJumper(evo.Phenome{ID: 7, Network: net})} {
+		gopher := NewGopher()
+		gopher.jumper = jumper
+		if got := networkOf(gopher); got != net {
+			t.Errorf("unexpected network for %T: %v", jumper, got)
+		}
+	}
+
+	gopher := NewGopher()
+	gopher.jumper = neverJumper{}
+	if got := networkOf(gopher); got != nil {
+		t.Errorf("unexpected network %v", got)
+	}
+}
+
+func TestGame_watched(t *testing.T) {
+	g := NewGame(100, 1, 3)
+	if g.watched() != nil {
+		t.Error("watching an empty game")
+	}
+	for i := range g.Gopher {
+		g.Gopher[i] = NewGopher()
+		g.Gopher[i].init()
+		g.Gopher[i].x16 = 1600 * i
+	}
+	g.Gopher[2].isDead = true
+	if g.watched() != g.Gopher[1] {
+		t.Error("not watching the best gopher flying")
+	}
+	g.selected = g.Gopher[0]
+	if g.watched() != g.Gopher[0] {
+		t.Error("not watching the selected gopher")
+	}
+}
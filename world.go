@@ -0,0 +1,175 @@
+package neatflappy
+
+import (
+	"math/rand"
+	"time"
+)
+
+// NewLevel returns the level flown by the n-th generation of the training. The seed
+// draws the random pipes used by the advanced levels; 0 keeps the default ones.
+func NewLevel(n int, seed int64) Level {
+	if n >= level2 && seed != 0 {
+		return ownPipes(progression(n), seed)
+	}
+	return progression(n)
+}
+
+// levelOf returns the level flown by the generation of a training run with the seed,
+// counting from 0
+func levelOf(seed int64, generation int) Level {
+	return NewLevel(progressionLevel(seed, generation))
+}
+
+// progressionLevel returns the number and the seed of the level flown by the generation
+// of a training run, counting from 0. The advanced levels draw new random pipes every 20
+// generations, mixing the seed of the run with the generation. The pipes depend only on
+// both, so every game of the run flying the generation gets the same level and NewLevel
+// builds it again from the number and the seed. The run seed 0 flies the same pipes in
+// every run.
+func progressionLevel(seed int64, generation int) (int, int64) {
+	return generation + 1, seed*1000003 + int64(generation/20)
+}
+
+// NewRunSeed draws the seed of the random pipes flown by a training run
+func NewRunSeed() int64 {
+	return rand.New(rand.NewSource(time.Now().UnixNano())).Int63n(1<<31) + 1
+}
+
+// progression returns the n-th level of the progression, with the default pipes
+func progression(n int) Level {
+	if n < level2 {
+		return Level1(n)
+	}
+	return Level6(n)
+}
+
+// pipesLevel is a level flying its own random pipes instead of the shared default ones,
+// so drawing the pipes of a level never changes the ones of the levels being flown
+type pipesLevel struct {
+	Level
+	pipeTileYs []int
+}
+
+// ownPipes returns the level with its own pipes, drawn from the seed
+func ownPipes(l Level, seed int64) Level {
+	r := rand.New(rand.NewSource(seed))
+	pipes := make([]int, len(pipeTileYs))
+	for i := range pipes {
+		pipes[i] = r.Intn(6) + 2
+	}
+	return pipesLevel{Level: l, pipeTileYs: pipes}
+}
+
+// PipeAt places the pipes of the level where the default ones would be
+func (l pipesLevel) PipeAt(tileX int) (int, bool) {
+	if _, ok := l.Level.PipeAt(tileX); !ok {
+		return 0, false
+	}
+	i := floorDiv(tileX-pipeStartOffsetX, pipeIntervalX)
+	return l.pipeTileYs[floorMod(i, len(l.pipeTileYs))], true
+}
+
+// The physics of the flight, in 1/16 of pixel per tick
+const (
+	scrollSpeed16  = 32
+	jumpVelocity16 = -96
+	gravity16      = 4
+	maxVelocity16  = 96
+)
+
+// world is the level being flown and the camera following the gophers
+type world struct {
+	level Level
+
+	// Camera
+	cameraX int
+	cameraY int
+}
+
+// advance moves the camera one tick forward and reports if the gophers are passing a pipe
+func (w *world) advance() bool {
+	w.cameraX += 2
+	_, successed := w.pipeAt(w.cameraX - 2)
+	return successed && (w.cameraX > pipeStartOffsetX) && (floorMod(w.cameraX-pipeStartOffsetX, pipeIntervalX) < 2)
+}
+
+func (w *world) update(gopher *Gopher) {
+	shloudJump := gopher.jump(w.scan(gopher))
+	gopher.x16 += scrollSpeed16
+	if shloudJump {
+		gopher.jumps++
+		gopher.vy16 = jumpVelocity16
+		// jumpPlayer.Rewind()
+		// jumpPlayer.Play()
+	}
+	gopher.y16 += gopher.vy16 + 2
+
+	// Gravity
+	gopher.vy16 += gravity16
+	if gopher.vy16 > maxVelocity16 {
+		gopher.vy16 = maxVelocity16
+	}
+}
+
+func (w *world) pipeAt(tileX int) (tileY int, ok bool) {
+	return w.level.PipeAt(tileX)
+}
+
+func (w *world) scan(gopher *Gopher) []int {
+	x0 := floorDiv(gopher.x16, 16) + (gopherImageWidth-gopherWidth)/2
+	y0 := floorDiv(gopher.y16, 16) + (gopherImageHeight-gopherHeight)/2
+	y1 := y0 + gopherHeight
+	res := []int{8, 0, 8, 0}
+	if y0 < -tileSize*4 {
+		return res
+	}
+	if y1 >= ScreenHeight-tileSize {
+		return res
+	}
+	xMin := floorDiv(x0-pipeWidth, tileSize)
+
+	for x := xMin; x < xMin+14; x++ {
+		if y, ok := w.pipeAt(x); ok {
+			res = append(res, x-xMin-7, y)
+		}
+	}
+	if len(res) == 4 {
+		return res
+	}
+	return res[len(res)-4:]
+}
+
+func (w *world) hit(gopher *Gopher) bool {
+	x0 := floorDiv(gopher.x16, 16) + (gopherImageWidth-gopherWidth)/2
+	y0 := floorDiv(gopher.y16, 16) + (gopherImageHeight-gopherHeight)/2
+	x1 := x0 + gopherWidth
+	y1 := y0 + gopherHeight
+	if y0 < -tileSize*4 {
+		return true
+	}
+	if y1 >= ScreenHeight-tileSize {
+		return true
+	}
+	xMin := floorDiv(x0-pipeWidth, tileSize)
+	xMax := floorDiv(x0+gopherWidth, tileSize)
+
+	for x := xMin; x <= xMax; x++ {
+		y, ok := w.pipeAt(x)
+		if !ok {
+			continue
+		}
+		if x0 >= x*tileSize+pipeWidth {
+			continue
+		}
+		if x1 < x*tileSize {
+			continue
+		}
+		if y0 < y*tileSize {
+			return true
+		}
+		if y1 >= (y+pipeGapY)*tileSize {
+			return true
+		}
+	}
+	return false
+}